@@ -0,0 +1,91 @@
+package rcon
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Adaptive timeout definitions.
+const (
+	// MinAdaptiveTimeout is the floor of the adaptive timeout.
+	MinAdaptiveTimeout = 500 * time.Millisecond
+
+	// adaptiveWindow is the number of latest command latencies which the
+	// adaptive timeout is computed from.
+	adaptiveWindow = 50
+
+	// adaptiveMinSamples is the number of latencies which are needed before
+	// the adaptive timeout replaces the deadline.
+	adaptiveMinSamples = 5
+)
+
+// latencyWindow is a rolling window of command latencies which is safe for
+// concurrent use.
+type latencyWindow struct {
+	mu      sync.Mutex
+	samples [adaptiveWindow]time.Duration
+	n       int
+	next    int
+}
+
+// add adds latency to the window replacing the oldest one if it is full.
+func (w *latencyWindow) add(latency time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.samples[w.next] = latency
+	w.next = (w.next + 1) % adaptiveWindow
+	w.n = min(w.n+1, adaptiveWindow)
+}
+
+// timeout returns the mean plus four standard deviations of latencies and
+// false if there are not enough latencies yet.
+func (w *latencyWindow) timeout() (time.Duration, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.n < adaptiveMinSamples {
+		return 0, false
+	}
+
+	var sum float64
+	for _, sample := range w.samples[:w.n] {
+		sum += float64(sample)
+	}
+
+	mean := sum / float64(w.n)
+
+	var variance float64
+	for _, sample := range w.samples[:w.n] {
+		variance += (float64(sample) - mean) * (float64(sample) - mean)
+	}
+
+	stddev := math.Sqrt(variance / float64(w.n))
+
+	return time.Duration(mean + 4*stddev), true
+}
+
+// CurrentTimeout returns the read timeout of the next command. With
+// SetAdaptiveTimeout it is computed from latencies of latest commands as their
+// mean plus four standard deviations, limited by MinAdaptiveTimeout and the
+// deadline of SetDeadline. Until enough commands have finished, and without
+// SetAdaptiveTimeout, it is the deadline.
+func (c *Conn) CurrentTimeout() time.Duration {
+	if !c.settings.adaptiveTimeout {
+		return c.settings.deadline
+	}
+
+	timeout, ok := c.latencies.timeout()
+	if !ok {
+		return c.settings.deadline
+	}
+
+	timeout = max(timeout, MinAdaptiveTimeout)
+
+	if c.settings.deadline > 0 {
+		timeout = min(timeout, c.settings.deadline)
+	}
+
+	return timeout
+}
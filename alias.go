@@ -0,0 +1,85 @@
+package rcon
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrAliasArgument is returned when a command doesn't have an argument which
+// is used by the template of its alias.
+var ErrAliasArgument = errors.New("missing alias argument")
+
+// expandAlias returns command with the longest matching alias set by
+// SetAliases replaced by its template.
+func (c *Conn) expandAlias(command string) (string, error) {
+	if len(c.settings.aliases) == 0 {
+		return command, nil
+	}
+
+	words := strings.Fields(command)
+
+	for n := len(words); n > 0; n-- {
+		template, ok := c.settings.aliases[strings.Join(words[:n], " ")]
+		if !ok {
+			continue
+		}
+
+		expanded, err := expandTemplate(template, words[n:])
+		if err != nil {
+			return command, fmt.Errorf("rcon: alias %q: %w", strings.Join(words[:n], " "), err)
+		}
+
+		return expanded, nil
+	}
+
+	return command, nil
+}
+
+// expandTemplate replaces placeholders of alias template by args. Args are
+// appended to the template without placeholders.
+func expandTemplate(template string, args []string) (string, error) {
+	var (
+		b           strings.Builder
+		placeholder bool
+	)
+
+	for i := 0; i < len(template); i++ {
+		if template[i] != '$' || i == len(template)-1 {
+			b.WriteByte(template[i])
+
+			continue
+		}
+
+		switch next := template[i+1]; {
+		case next == '$':
+			b.WriteByte('$')
+		case next == '*':
+			b.WriteString(strings.Join(args, " "))
+
+			placeholder = true
+		case next >= '1' && next <= '9':
+			n := int(next - '0')
+			if n > len(args) {
+				return "", fmt.Errorf("%w $%d", ErrAliasArgument, n)
+			}
+
+			b.WriteString(args[n-1])
+
+			placeholder = true
+		default:
+			b.WriteByte('$')
+
+			continue
+		}
+
+		i++
+	}
+
+	if !placeholder && len(args) > 0 {
+		b.WriteByte(' ')
+		b.WriteString(strings.Join(args, " "))
+	}
+
+	return b.String(), nil
+}
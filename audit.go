@@ -0,0 +1,115 @@
+package rcon
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// auditLogBuffer is the number of audit records which are queued for writing
+// before new records are dropped.
+const auditLogBuffer = 256
+
+// AuditRecord is a record of a command which is written to the audit log set
+// by SetAuditLog as a JSON line, for example:
+//
+//	{"time":"2024-01-02T15:04:05.123Z","command":"kick Steve","response_size":12,"local_addr":"10.0.0.1:50000","remote_addr":"10.0.0.2:25575"}
+//
+// Error is omitted if the command succeeded.
+type AuditRecord struct {
+	Time         time.Time `json:"time"`
+	Command      string    `json:"command"`
+	ResponseSize int       `json:"response_size"`
+	Error        string    `json:"error,omitempty"`
+	LocalAddr    string    `json:"local_addr"`
+	RemoteAddr   string    `json:"remote_addr"`
+}
+
+// auditLog writes audit records to w in a separate goroutine, so a slow writer
+// doesn't stall commands.
+type auditLog struct {
+	w       io.Writer
+	records chan AuditRecord
+	done    chan struct{}
+	start   sync.Once
+	mu      sync.Mutex
+	closed  bool
+}
+
+// newAuditLog returns audit log writing to w or nil if w is nil.
+func newAuditLog(w io.Writer) *auditLog {
+	if w == nil {
+		return nil
+	}
+
+	return &auditLog{w: w, records: make(chan AuditRecord, auditLogBuffer), done: make(chan struct{})}
+}
+
+// log queues record for writing. The record is dropped if the queue is full
+// or the log is closed.
+func (l *auditLog) log(record AuditRecord) {
+	l.start.Do(func() {
+		go l.run()
+	})
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return
+	}
+
+	select {
+	case l.records <- record:
+	default:
+	}
+}
+
+// run writes queued records until the log is closed. Write errors are ignored.
+func (l *auditLog) run() {
+	defer close(l.done)
+
+	encoder := json.NewEncoder(l.w)
+
+	for record := range l.records {
+		_ = encoder.Encode(record)
+	}
+}
+
+// close stops the log after queued records are written. It is safe to call
+// on nil log.
+func (l *auditLog) close() {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	l.closed = true
+	close(l.records)
+	l.mu.Unlock()
+
+	// Wait for the writer only if it has been started.
+	l.start.Do(func() {
+		close(l.done)
+	})
+
+	<-l.done
+}
+
+// auditRecord returns AuditRecord of the finished command event.
+func (c *Conn) auditRecord(event CommandEvent) AuditRecord {
+	record := AuditRecord{
+		Time:         time.Now().UTC(),
+		Command:      event.Command,
+		ResponseSize: event.ResponseSize,
+		LocalAddr:    c.LocalAddr().String(),
+		RemoteAddr:   c.RemoteAddr().String(),
+	}
+
+	if event.Err != nil {
+		record.Error = event.Err.Error()
+	}
+
+	return record
+}
@@ -0,0 +1,118 @@
+package rcon_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon/rcontest"
+)
+
+func TestSetAuditLog(t *testing.T) {
+	server := rcontest.NewServer(rcontest.SetCommandHandler(commandHandler))
+	defer server.Close()
+
+	var log bytes.Buffer
+
+	conn, err := rcon.Dial(server.Addr(), "", rcon.SetAuditLog(&log))
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	if _, err := conn.Execute("help"); err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	if _, err := conn.Execute(""); !errors.Is(err, rcon.ErrCommandEmpty) {
+		t.Fatalf("got err %q, want %q", err, rcon.ErrCommandEmpty)
+	}
+
+	localAddr, remoteAddr := conn.LocalAddr().String(), conn.RemoteAddr().String()
+
+	// Close waits for queued records.
+	if err := conn.Close(); err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	records := readAuditRecords(t, &log)
+
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want %d", len(records), 2)
+	}
+
+	if records[0].Command != "help" || records[0].ResponseSize != len("lorem ipsum dolor sit amet") || records[0].Error != "" {
+		t.Errorf("got record %+v, want successful help command", records[0])
+	}
+
+	if records[0].LocalAddr != localAddr || records[0].RemoteAddr != remoteAddr || records[0].Time.IsZero() {
+		t.Errorf("got record %+v, want time and addresses %s, %s", records[0], localAddr, remoteAddr)
+	}
+
+	if records[1].Error != rcon.ErrCommandEmpty.Error() {
+		t.Errorf("got record error %q, want %q", records[1].Error, rcon.ErrCommandEmpty)
+	}
+}
+
+func TestSetAuditLog_Paths(t *testing.T) {
+	server := rcontest.NewServer(rcontest.SetCommandHandler(commandHandler))
+	defer server.Close()
+
+	var log bytes.Buffer
+
+	conn, err := rcon.Dial(server.Addr(), "", rcon.SetAuditLog(&log))
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	stream, err := conn.ExecuteStream("help")
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	stream.Close()
+
+	if _, err := conn.ExecutePipeline([]string{"help", "help"}); err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	if err := conn.ExecuteFunc("help", func(string) error { return nil }); err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	if err := conn.Close(); err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	records := readAuditRecords(t, &log)
+
+	if len(records) != 4 {
+		t.Fatalf("got %d records, want %d", len(records), 4)
+	}
+
+	for _, record := range records {
+		if record.Command != "help" || record.ResponseSize != len("lorem ipsum dolor sit amet") || record.Error != "" {
+			t.Errorf("got record %+v, want successful help command", record)
+		}
+	}
+}
+
+// readAuditRecords decodes JSON lines written by the audit log.
+func readAuditRecords(t *testing.T, log *bytes.Buffer) []rcon.AuditRecord {
+	t.Helper()
+
+	var records []rcon.AuditRecord
+
+	scanner := bufio.NewScanner(log)
+	for scanner.Scan() {
+		var record rcon.AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		records = append(records, record)
+	}
+
+	return records
+}
@@ -0,0 +1,29 @@
+package rcon
+
+import (
+	"math/rand"
+	"time"
+)
+
+// reconnectDelay returns the wait before reconnect attempt, counted from 0.
+// Without SetReconnectBackoff it is the fixed backoff of SetAutoReconnect.
+// Otherwise the backoff doubles with each attempt up to the maximum and is
+// reduced by a random part of up to the jitter fraction.
+func (c *Conn) reconnectDelay(attempt int) time.Duration {
+	delay := c.settings.reconnectBackoff
+	if c.settings.reconnectBackoffMax <= 0 || delay <= 0 {
+		return delay
+	}
+
+	for i := 0; i < attempt && delay < c.settings.reconnectBackoffMax; i++ {
+		delay *= 2
+	}
+
+	delay = min(delay, c.settings.reconnectBackoffMax)
+
+	if jitter := min(max(c.settings.reconnectJitter, 0), 1); jitter > 0 {
+		delay -= time.Duration(rand.Float64() * jitter * float64(delay))
+	}
+
+	return delay
+}
@@ -0,0 +1,63 @@
+package rcon
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConn_reconnectDelay(t *testing.T) {
+	t.Run("fixed", func(t *testing.T) {
+		c := Conn{settings: DefaultSettings}
+		SetAutoReconnect(3, 50*time.Millisecond)(&c.settings)
+
+		for attempt := 0; attempt < 3; attempt++ {
+			if got := c.reconnectDelay(attempt); got != 50*time.Millisecond {
+				t.Errorf("got delay %s, want %s", got, 50*time.Millisecond)
+			}
+		}
+	})
+
+	t.Run("exponential", func(t *testing.T) {
+		c := Conn{settings: DefaultSettings}
+		SetReconnectBackoff(100*time.Millisecond, time.Second, 0)(&c.settings)
+
+		want := []time.Duration{
+			100 * time.Millisecond,
+			200 * time.Millisecond,
+			400 * time.Millisecond,
+			800 * time.Millisecond,
+			time.Second,
+			time.Second,
+		}
+
+		for attempt, delay := range want {
+			if got := c.reconnectDelay(attempt); got != delay {
+				t.Errorf("got attempt %d delay %s, want %s", attempt, got, delay)
+			}
+		}
+	})
+
+	t.Run("jitter", func(t *testing.T) {
+		c := Conn{settings: DefaultSettings}
+		SetReconnectBackoff(100*time.Millisecond, time.Second, 0.5)(&c.settings)
+
+		jittered := false
+
+		for i := 0; i < 100; i++ {
+			for attempt := 0; attempt < 6; attempt++ {
+				ceiling := min(100*time.Millisecond<<attempt, time.Second)
+
+				got := c.reconnectDelay(attempt)
+				if got < ceiling/2 || got > ceiling {
+					t.Fatalf("got attempt %d delay %s, want from %s to %s", attempt, got, ceiling/2, ceiling)
+				}
+
+				jittered = jittered || got != ceiling
+			}
+		}
+
+		if !jittered {
+			t.Error("got delays without jitter")
+		}
+	})
+}
@@ -0,0 +1,107 @@
+package rcon
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// BalanceStrategy is the way BalancedConn chooses a backend for a command.
+type BalanceStrategy int
+
+// Known balance strategies.
+const (
+	// RoundRobin sends commands to backends in turn.
+	RoundRobin BalanceStrategy = iota
+
+	// LeastInFlight sends commands to the backend which executes the fewest
+	// commands at the moment.
+	LeastInFlight
+)
+
+// BalancedConn spreads commands to a single logical server over several RCON
+// endpoints, for example of a proxy. Closed backends are skipped, backends
+// whose latest command failed are used only if no other backend is left, so
+// they can recover. The zero BalancedConn has no backends.
+type BalancedConn struct {
+	Conns    []*Conn
+	Strategy BalanceStrategy
+
+	once     sync.Once
+	next     atomic.Uint64
+	inFlight []atomic.Int64
+}
+
+// Execute executes command on a backend chosen by the strategy. If all
+// backends are closed, it returns ErrNoHealthyBackend.
+func (b *BalancedConn) Execute(command string) (string, error) {
+	return b.ExecuteContext(context.Background(), command)
+}
+
+// ExecuteContext works like Execute but uses ctx like Conn.ExecuteContext.
+func (b *BalancedConn) ExecuteContext(ctx context.Context, command string) (string, error) {
+	b.once.Do(func() {
+		b.inFlight = make([]atomic.Int64, len(b.Conns))
+	})
+
+	i, err := b.pick()
+	if err != nil {
+		return "", err
+	}
+
+	b.inFlight[i].Add(1)
+	defer b.inFlight[i].Add(-1)
+
+	return b.Conns[i].ExecuteContext(ctx, command)
+}
+
+// Close closes all backends and returns the joined errors.
+func (b *BalancedConn) Close() error {
+	errs := make([]error, 0, len(b.Conns))
+
+	for _, conn := range b.Conns {
+		errs = append(errs, conn.Close())
+	}
+
+	return errors.Join(errs...)
+}
+
+// pick returns the index of the backend for the next command. Healthy backends
+// are preferred over errored ones.
+func (b *BalancedConn) pick() (int, error) {
+	n := len(b.Conns)
+	if n == 0 {
+		return 0, ErrNoHealthyBackend
+	}
+
+	start := int((b.next.Add(1) - 1) % uint64(n))
+	healthy, errored := -1, -1
+
+	for k := 0; k < n; k++ {
+		i := (start + k) % n
+
+		conn := b.Conns[i]
+		if conn.IsClosed() {
+			continue
+		}
+
+		best := &healthy
+		if conn.State() == StateErrored {
+			best = &errored
+		}
+
+		if *best < 0 || (b.Strategy == LeastInFlight && b.inFlight[i].Load() < b.inFlight[*best].Load()) {
+			*best = i
+		}
+	}
+
+	switch {
+	case healthy >= 0:
+		return healthy, nil
+	case errored >= 0:
+		return errored, nil
+	default:
+		return 0, ErrNoHealthyBackend
+	}
+}
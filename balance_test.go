@@ -0,0 +1,103 @@
+package rcon_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon/rcontest"
+)
+
+func TestBalancedConn_Execute(t *testing.T) {
+	newBalancedConn := func(t *testing.T, strategy rcon.BalanceStrategy, delays ...time.Duration) *rcon.BalancedConn {
+		t.Helper()
+
+		balanced := &rcon.BalancedConn{Strategy: strategy}
+
+		for i, delay := range delays {
+			response := string(rune('a' + i))
+
+			server := rcontest.NewServer(
+				rcontest.SetSettings(rcontest.Settings{CommandResponseDelay: delay}),
+				rcontest.SetCommandHandler(func(c *rcontest.Context) {
+					rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, response).WriteTo(c.Conn())
+				}),
+			)
+			t.Cleanup(server.Close)
+
+			conn, err := rcon.Dial(server.Addr(), "")
+			if err != nil {
+				t.Fatalf("got err %q, want %v", err, nil)
+			}
+
+			balanced.Conns = append(balanced.Conns, conn)
+		}
+
+		// Connections are closed before the servers, which wait for them.
+		t.Cleanup(func() { balanced.Close() })
+
+		return balanced
+	}
+
+	execute := func(t *testing.T, balanced *rcon.BalancedConn) string {
+		t.Helper()
+
+		result, err := balanced.Execute("status")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		return result
+	}
+
+	t.Run("round robin", func(t *testing.T) {
+		balanced := newBalancedConn(t, rcon.RoundRobin, 0, 0)
+
+		var results string
+		for i := 0; i < 4; i++ {
+			results += execute(t, balanced)
+		}
+
+		if results != "abab" {
+			t.Errorf("got results %q, want %q", results, "abab")
+		}
+	})
+
+	t.Run("least in flight", func(t *testing.T) {
+		balanced := newBalancedConn(t, rcon.LeastInFlight, 200*time.Millisecond, 0)
+
+		done := make(chan string)
+		go func() {
+			result, _ := balanced.Execute("status")
+			done <- result
+		}()
+
+		time.Sleep(50 * time.Millisecond)
+
+		if result := execute(t, balanced); result != "b" {
+			t.Errorf("got result %q, want %q", result, "b")
+		}
+
+		if result := <-done; result != "a" {
+			t.Errorf("got result %q, want %q", result, "a")
+		}
+	})
+
+	t.Run("closed backend", func(t *testing.T) {
+		balanced := newBalancedConn(t, rcon.RoundRobin, 0, 0)
+		balanced.Conns[0].Close()
+
+		for i := 0; i < 2; i++ {
+			if result := execute(t, balanced); result != "b" {
+				t.Errorf("got result %q, want %q", result, "b")
+			}
+		}
+
+		balanced.Conns[1].Close()
+
+		if _, err := balanced.Execute("status"); !errors.Is(err, rcon.ErrNoHealthyBackend) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrNoHealthyBackend)
+		}
+	})
+}
@@ -0,0 +1,86 @@
+package rcon
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// DefaultBanPatterns contains messages which servers of any game commonly send
+// to banned clients. They are checked together with the patterns of the game
+// type, see BanPatterns.
+var DefaultBanPatterns = []string{
+	"you are banned",
+	"you have been banned",
+	"banned from this server",
+	"your ip is banned",
+}
+
+// gameBanPatterns contains game specific messages sent to banned clients.
+var gameBanPatterns = map[GameType][]string{
+	GameSource:         {"banned by server"},
+	GameMinecraft:      {"your ip address is banned"},
+	GameRust:           {"kicked: banned"},
+	GameProjectZomboid: {"banned from server"},
+	GameConanExiles:    {"you are currently banned"},
+}
+
+// BanPatterns returns the built-in ban patterns of game, which are checked on
+// the auth response and on the last packet before the server drops the
+// connection. Pass them to SetBanPatterns to check command responses too.
+func BanPatterns(game GameType) []string {
+	patterns := make([]string, 0, len(DefaultBanPatterns)+len(gameBanPatterns[game]))
+	patterns = append(patterns, DefaultBanPatterns...)
+
+	return append(patterns, gameBanPatterns[game]...)
+}
+
+// checkBanned returns ErrBanned if command response body contains any of the
+// ban patterns set with SetBanPatterns.
+func (c *Conn) checkBanned(body []byte) error {
+	return matchBanned(body, c.settings.banPatterns)
+}
+
+// checkAuthBanned returns ErrBanned if auth response body or the last packet
+// before a disconnect contains any of the built-in ban patterns of the game
+// type or of the patterns set with SetBanPatterns.
+func (c *Conn) checkAuthBanned(body []byte) error {
+	if err := matchBanned(body, BanPatterns(c.settings.gameType)); err != nil {
+		return err
+	}
+
+	return c.checkBanned(body)
+}
+
+// checkDisconnectBanned returns ErrBanned if the server dropped the connection
+// with err right after sending a ban message.
+func (c *Conn) checkDisconnectBanned(err error) error {
+	if !isConnError(err) {
+		return nil
+	}
+
+	packet := c.lastPacket.Load()
+	if packet == nil {
+		return nil
+	}
+
+	return c.checkAuthBanned(packet.body)
+}
+
+// matchBanned returns ErrBanned if body contains any of patterns. Patterns are
+// matched case-insensitively.
+func matchBanned(body []byte, patterns []string) error {
+	if len(patterns) == 0 || len(body) == 0 {
+		return nil
+	}
+
+	body = bytes.ToLower(body)
+
+	for _, pattern := range patterns {
+		if pattern != "" && bytes.Contains(body, []byte(strings.ToLower(pattern))) {
+			return fmt.Errorf("%w: matched %q", ErrBanned, pattern)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package rcon
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidTemplate is returned by Broadcast and ExecutePaginated when the
+// command template doesn't contain exactly one %s verb.
+var ErrInvalidTemplate = errors.New("command template must contain exactly one %s")
+
+// Broadcast sends each of lines as a separate command made from cmdTemplate,
+// for example "say %s" or "servermsg %s", because most games reject or mangle
+// newlines in a single command. On the first failed line it returns an error
+// with the failed line number, lines after it are not sent. The broadcast is
+// limited by SetBatchDeadline.
+func (c *Conn) Broadcast(lines []string, cmdTemplate string) error {
+	if err := checkTemplate(cmdTemplate); err != nil {
+		return err
+	}
+
+	ctx, cancel := c.batchContext()
+	defer cancel()
+
+	for i, line := range lines {
+		if _, err := c.ExecuteContext(ctx, fmt.Sprintf(cmdTemplate, line)); err != nil {
+			return fmt.Errorf("rcon: broadcast line %d %q: %w", i+1, line, err)
+		}
+	}
+
+	return nil
+}
+
+// checkTemplate returns ErrInvalidTemplate if cmdTemplate doesn't contain
+// exactly one %s verb.
+func checkTemplate(cmdTemplate string) error {
+	if strings.Count(cmdTemplate, "%s") != 1 || strings.Count(cmdTemplate, "%") != 1 {
+		return fmt.Errorf("rcon: %w: %q", ErrInvalidTemplate, cmdTemplate)
+	}
+
+	return nil
+}
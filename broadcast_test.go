@@ -0,0 +1,65 @@
+package rcon_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon/rcontest"
+)
+
+func TestConn_Broadcast(t *testing.T) {
+	var commands []string
+
+	server := rcontest.NewServer(rcontest.SetCommandHandler(func(c *rcontest.Context) {
+		commands = append(commands, c.Request().Body())
+		rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, "").WriteTo(c.Conn())
+	}))
+	defer server.Close()
+
+	conn, err := rcon.Dial(server.Addr(), "")
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	t.Run("success", func(t *testing.T) {
+		commands = nil
+
+		if err := conn.Broadcast([]string{"Restart in 5 minutes", "100% safe"}, "say %s"); err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		commandsWant := []string{"say Restart in 5 minutes", "say 100% safe"}
+		if fmt.Sprint(commands) != fmt.Sprint(commandsWant) {
+			t.Errorf("got commands %q, want %q", commands, commandsWant)
+		}
+	})
+
+	t.Run("failed line", func(t *testing.T) {
+		commands = nil
+
+		err := conn.Broadcast([]string{"first", strings.Repeat("a", rcon.MaxCommandLen), "third"}, "say %s")
+		if !errors.Is(err, rcon.ErrCommandTooLong) {
+			t.Fatalf("got err %q, want %q", err, rcon.ErrCommandTooLong)
+		}
+
+		if !strings.Contains(err.Error(), "line 2") {
+			t.Errorf("got err %q, want failed line %d", err, 2)
+		}
+
+		if len(commands) != 1 {
+			t.Errorf("got %d sent commands, want %d", len(commands), 1)
+		}
+	})
+
+	t.Run("invalid template", func(t *testing.T) {
+		for _, template := range []string{"say", "say %s %s", "say %d"} {
+			if err := conn.Broadcast([]string{"hello"}, template); !errors.Is(err, rcon.ErrInvalidTemplate) {
+				t.Errorf("got err %q, want %q", err, rcon.ErrInvalidTemplate)
+			}
+		}
+	})
+}
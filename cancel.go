@@ -0,0 +1,37 @@
+package rcon
+
+import (
+	"context"
+)
+
+// Cancel interrupts the command which is currently executed, for example from
+// a goroutine handling a cancel button. The command returns ErrCanceled and
+// the connection stays usable, but like with ExecuteContext a rest of the
+// response is left unread. Cancel affects only the current command, it does
+// nothing if no command is executed.
+func (c *Conn) Cancel() {
+	c.cancelMu.Lock()
+	defer c.cancelMu.Unlock()
+
+	if c.cancelCommand != nil {
+		c.cancelCommand(ErrCanceled)
+	}
+}
+
+// cancelable returns ctx which is canceled by Cancel until the returned
+// function is called. c.mu must be held by the caller.
+func (c *Conn) cancelable(ctx context.Context) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+
+	c.cancelMu.Lock()
+	c.cancelCommand = cancel
+	c.cancelMu.Unlock()
+
+	return ctx, func() {
+		c.cancelMu.Lock()
+		c.cancelCommand = nil
+		c.cancelMu.Unlock()
+
+		cancel(nil)
+	}
+}
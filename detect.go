@@ -0,0 +1,50 @@
+package rcon
+
+import (
+	"regexp"
+)
+
+// gameProbe is a fingerprint of the game in the response to command.
+type gameProbe struct {
+	command string
+	pattern *regexp.Regexp
+	game    GameType
+}
+
+// gameProbes are checked in order, so more specific fingerprints of the same
+// command go first.
+var gameProbes = []gameProbe{
+	{command: "status", pattern: regexp.MustCompile(`(?m)^id\s+name\s+ping\s+connected`), game: GameRust},
+	{command: "status", pattern: regexp.MustCompile(`(?m)^(udp/ip\s*:|# userid)`), game: GameSource},
+	{command: "list", pattern: regexp.MustCompile(`^There are \d+( of a max of \d+|/\d+) players online`), game: GameMinecraft},
+	{command: "players", pattern: regexp.MustCompile(`^Players connected \(\d+\)`), game: GameProjectZomboid},
+}
+
+// DetectGameType guesses the game type of the server by executing the
+// fingerprint commands "status", "list" and "players" and matching their
+// responses. It is heuristic and sends real commands, which have no side
+// effects on known games. GameUnknown is returned if no response matches.
+// Conan Exiles is not detected, because conn must be dialed with
+// GameConanExiles to execute commands at all.
+func DetectGameType(conn *Conn) (GameType, error) {
+	responses := make(map[string]string)
+
+	for _, probe := range gameProbes {
+		response, ok := responses[probe.command]
+		if !ok {
+			var err error
+
+			if response, err = conn.Execute(probe.command); err != nil {
+				return GameUnknown, err
+			}
+
+			responses[probe.command] = response
+		}
+
+		if probe.pattern.MatchString(response) {
+			return probe.game, nil
+		}
+	}
+
+	return GameUnknown, nil
+}
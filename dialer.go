@@ -0,0 +1,60 @@
+package rcon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Dialer contains password and options for connecting to servers. The zero
+// Dialer connects with an empty password and DefaultSettings.
+type Dialer struct {
+	Password string
+	Options  []Option
+
+	// StrictWarmup makes DialWarm fail if any warmup command fails.
+	StrictWarmup bool
+}
+
+// Dial connects to the server at address like Dial function.
+func (d *Dialer) Dial(address string) (*Conn, error) {
+	return d.DialContext(context.Background(), address)
+}
+
+// DialContext connects to the server at address like DialContext function.
+func (d *Dialer) DialContext(ctx context.Context, address string) (*Conn, error) {
+	return DialContext(ctx, address, d.Password, d.Options...)
+}
+
+// DialWarm connects to the server at address and executes warmup commands,
+// for example to prefetch data which is shown right after connecting.
+// Responses are returned in the order of commands. If a warmup command fails,
+// the connection stays open and is returned with the responses and the joined
+// errors of failed commands. If StrictWarmup is set, the connection is closed
+// on the first failed command and nil is returned instead.
+func (d *Dialer) DialWarm(address string, warmup []string) (*Conn, []string, error) {
+	conn, err := d.Dial(address)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	responses := make([]string, len(warmup))
+
+	var errs []error
+
+	for i, command := range warmup {
+		if responses[i], err = conn.Execute(command); err != nil {
+			err = fmt.Errorf("rcon: warmup command %q: %w", command, err)
+
+			if d.StrictWarmup {
+				_ = conn.Close()
+
+				return nil, responses[:i+1], err
+			}
+
+			errs = append(errs, err)
+		}
+	}
+
+	return conn, responses, errors.Join(errs...)
+}
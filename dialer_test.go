@@ -0,0 +1,72 @@
+package rcon_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon/rcontest"
+)
+
+func TestDialer_DialWarm(t *testing.T) {
+	server := rcontest.NewServer(
+		rcontest.SetSettings(rcontest.Settings{Password: "password"}),
+		rcontest.SetCommandHandler(commandHandler),
+	)
+	defer server.Close()
+
+	t.Run("success", func(t *testing.T) {
+		dialer := rcon.Dialer{Password: "password"}
+
+		conn, responses, err := dialer.DialWarm(server.Addr(), []string{"help", "status"})
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		responsesWant := []string{"lorem ipsum dolor sit amet", "unknown command"}
+		if fmt.Sprint(responses) != fmt.Sprint(responsesWant) {
+			t.Errorf("got responses %q, want %q", responses, responsesWant)
+		}
+	})
+
+	t.Run("failed warmup command", func(t *testing.T) {
+		dialer := rcon.Dialer{Password: "password"}
+
+		conn, responses, err := dialer.DialWarm(server.Addr(), []string{"", "help"})
+		if !errors.Is(err, rcon.ErrCommandEmpty) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrCommandEmpty)
+		}
+
+		if conn == nil {
+			t.Fatalf("got nil conn, want open conn")
+		}
+		defer conn.Close()
+
+		if responses[1] != "lorem ipsum dolor sit amet" {
+			t.Errorf("got response %q, want %q", responses[1], "lorem ipsum dolor sit amet")
+		}
+	})
+
+	t.Run("strict warmup", func(t *testing.T) {
+		dialer := rcon.Dialer{Password: "password", StrictWarmup: true}
+
+		conn, _, err := dialer.DialWarm(server.Addr(), []string{"help", ""})
+		if !errors.Is(err, rcon.ErrCommandEmpty) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrCommandEmpty)
+		}
+
+		if conn != nil {
+			t.Errorf("got conn %v, want nil", conn)
+		}
+	})
+
+	t.Run("authentication failed", func(t *testing.T) {
+		dialer := rcon.Dialer{Password: "wrong"}
+
+		if _, _, err := dialer.DialWarm(server.Addr(), []string{"help"}); !errors.Is(err, rcon.ErrAuthFailed) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrAuthFailed)
+		}
+	})
+}
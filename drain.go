@@ -0,0 +1,32 @@
+package rcon
+
+import (
+	"context"
+)
+
+// Drain stops the connection from accepting new commands and closes it after
+// the in-flight command has finished, for example before an orchestrated
+// server restart. Commands called after Drain return ErrDraining. Unlike
+// Close, which interrupts the in-flight command, Drain waits for it until ctx
+// is done. Then the connection is closed anyway and ctx.Err() is returned.
+func (c *Conn) Drain(ctx context.Context) error {
+	c.draining.Store(true)
+
+	idle := make(chan struct{})
+
+	go func() {
+		// Commands waiting for the lock return ErrDraining right away.
+		c.mu.Lock()
+		c.mu.Unlock()
+		close(idle)
+	}()
+
+	select {
+	case <-idle:
+		return c.Close()
+	case <-ctx.Done():
+		_ = c.Close()
+
+		return ctx.Err()
+	}
+}
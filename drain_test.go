@@ -0,0 +1,117 @@
+package rcon_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon/rcontest"
+)
+
+func TestConn_Drain(t *testing.T) {
+	newServer := func(received chan<- struct{}, release <-chan struct{}) *rcontest.Server {
+		return rcontest.NewServer(rcontest.SetCommandHandler(func(c *rcontest.Context) {
+			received <- struct{}{}
+			<-release
+			rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, "done").WriteTo(c.Conn())
+		}))
+	}
+
+	t.Run("in-flight command finishes", func(t *testing.T) {
+		received, release := make(chan struct{}, 1), make(chan struct{})
+
+		server := newServer(received, release)
+		defer server.Close()
+
+		conn, err := rcon.Dial(server.Addr(), "")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		type result struct {
+			response string
+			err      error
+		}
+
+		inFlight := make(chan result, 1)
+
+		go func() {
+			response, err := conn.Execute("save")
+			inFlight <- result{response: response, err: err}
+		}()
+
+		<-received
+
+		drained := make(chan error, 1)
+
+		go func() {
+			drained <- conn.Drain(context.Background())
+		}()
+
+		// Let Drain mark the connection as draining before the next command.
+		time.Sleep(20 * time.Millisecond)
+
+		refused := make(chan error, 1)
+
+		go func() {
+			_, err := conn.Execute("status")
+			refused <- err
+		}()
+
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+
+		if got := <-inFlight; got.err != nil || got.response != "done" {
+			t.Errorf("got response %q err %v, want %q %v", got.response, got.err, "done", nil)
+		}
+
+		if err := <-refused; !errors.Is(err, rcon.ErrDraining) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrDraining)
+		}
+
+		if err := <-drained; err != nil {
+			t.Errorf("got err %q, want %v", err, nil)
+		}
+
+		if !conn.IsClosed() {
+			t.Error("got connection open, want closed")
+		}
+	})
+
+	t.Run("context done", func(t *testing.T) {
+		received, release := make(chan struct{}, 1), make(chan struct{})
+
+		server := newServer(received, release)
+		defer server.Close()
+		defer close(release)
+
+		conn, err := rcon.Dial(server.Addr(), "")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		inFlight := make(chan error, 1)
+
+		go func() {
+			_, err := conn.Execute("save")
+			inFlight <- err
+		}()
+
+		<-received
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		if err := conn.Drain(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("got err %q, want %q", err, context.DeadlineExceeded)
+		}
+
+		if err := <-inFlight; !errors.Is(err, rcon.ErrConnClosed) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrConnClosed)
+		}
+	})
+}
@@ -0,0 +1,34 @@
+package rcon
+
+import (
+	"strings"
+)
+
+// promptSuffixes are the last characters of prompts which servers put before
+// echoed commands.
+const promptSuffixes = ">$#]"
+
+// stripCommandEcho removes the first line of response if it is command as
+// given or as sent after aliases and the command pipeline.
+func stripCommandEcho(command string, sent string, response string) string {
+	line, rest, _ := strings.Cut(response, "\n")
+	line = strings.TrimRight(line, "\r")
+
+	if isCommandEcho(line, command) || (sent != command && isCommandEcho(line, sent)) {
+		return rest
+	}
+
+	return response
+}
+
+// isCommandEcho reports whether line is command optionally after a prompt.
+func isCommandEcho(line string, command string) bool {
+	command = strings.TrimSpace(command)
+	if command == "" || !strings.HasSuffix(line, command) {
+		return false
+	}
+
+	prompt := strings.TrimRight(strings.TrimSuffix(line, command), " ")
+
+	return prompt == "" || strings.ContainsRune(promptSuffixes, rune(prompt[len(prompt)-1]))
+}
@@ -0,0 +1,38 @@
+package rcon
+
+// Executor executes commands on a server. It is implemented by *Conn and may
+// be mocked in tests of code which executes commands.
+type Executor interface {
+	Execute(command string) (string, error)
+	Close() error
+}
+
+// Factory creates connections, so code which connects to servers can be tested
+// with a mock Factory instead of calling Dial directly.
+type Factory interface {
+	Dial(address string, password string) (Executor, error)
+}
+
+// FactoryFunc is an adapter to use a function as Factory.
+type FactoryFunc func(address string, password string) (Executor, error)
+
+// Dial calls f(address, password).
+func (f FactoryFunc) Dial(address string, password string) (Executor, error) {
+	return f(address, password)
+}
+
+// DefaultFactory is Factory which connects with Dial and DefaultSettings.
+var DefaultFactory Factory = NewFactory()
+
+// NewFactory returns Factory which connects with Dial and options.
+func NewFactory(options ...Option) Factory {
+	return FactoryFunc(func(address string, password string) (Executor, error) {
+		conn, err := Dial(address, password, options...)
+		if err != nil {
+			// A nil *Conn would be a non-nil Executor.
+			return nil, err
+		}
+
+		return conn, nil
+	})
+}
@@ -0,0 +1,45 @@
+package rcon_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon/rcontest"
+)
+
+func TestFactory(t *testing.T) {
+	server := rcontest.NewServer(
+		rcontest.SetSettings(rcontest.Settings{Password: "password"}),
+		rcontest.SetCommandHandler(commandHandler),
+	)
+	defer server.Close()
+
+	t.Run("default", func(t *testing.T) {
+		conn, err := rcon.DefaultFactory.Dial(server.Addr(), "password")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		result, err := conn.Execute("help")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if result != "lorem ipsum dolor sit amet" {
+			t.Errorf("got result %q, want %q", result, "lorem ipsum dolor sit amet")
+		}
+	})
+
+	t.Run("auth failed", func(t *testing.T) {
+		conn, err := rcon.NewFactory().Dial(server.Addr(), "wrong")
+		if !errors.Is(err, rcon.ErrAuthFailed) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrAuthFailed)
+		}
+
+		if conn != nil {
+			t.Errorf("got conn %v, want %v", conn, nil)
+		}
+	})
+}
@@ -0,0 +1,134 @@
+package rcon
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// GameType is a kind of game server, which is used to resolve game specific
+// defaults.
+type GameType int
+
+// Known game types.
+const (
+	// GameUnknown is the zero GameType for servers without game specific
+	// defaults.
+	GameUnknown GameType = iota
+	GameSource
+	GameMinecraft
+	GameRust
+	GameProjectZomboid
+
+	// GameConanExiles accepts responses with fixed id 42, which Conan Exiles
+	// sends regardless of the request id. Multi-packet responses are not
+	// supported because the sentinel can't be told apart from the response.
+	GameConanExiles
+)
+
+// conanExilesID is the id of all response packets of Conan Exiles.
+const conanExilesID int32 = 42
+
+// ErrMissingPort is returned when address has no port and the default port
+// can't be resolved because the game type is not set.
+var ErrMissingPort = errors.New("missing port in address, set port or game type")
+
+// ErrInvalidAddress is returned when address is not in host:port format with
+// a port number from 1 to 65535.
+var ErrInvalidAddress = errors.New("invalid address, want host:port")
+
+// defaultPorts contains default RCON ports of known game types.
+var defaultPorts = map[GameType]int{
+	GameSource:         27015,
+	GameMinecraft:      25575,
+	GameRust:           28016,
+	GameProjectZomboid: 16261,
+	GameConanExiles:    25575,
+}
+
+// DefaultPort returns the default RCON port of the game or 0 if it is unknown.
+func DefaultPort(game GameType) int {
+	return defaultPorts[game]
+}
+
+// String returns the game type name.
+func (g GameType) String() string {
+	switch g {
+	case GameSource:
+		return "source"
+	case GameMinecraft:
+		return "minecraft"
+	case GameRust:
+		return "rust"
+	case GameProjectZomboid:
+		return "projectzomboid"
+	case GameConanExiles:
+		return "conanexiles"
+	default:
+		return "unknown"
+	}
+}
+
+// resolveAddress adds the default port of the game to address without port
+// and validates address before dialing, so typos fail fast.
+func resolveAddress(address string, game GameType) (string, error) {
+	_, port, err := net.SplitHostPort(address)
+	if err == nil {
+		return address, checkPort(address, port)
+	}
+
+	host, ok := hostWithoutPort(address)
+	if !ok {
+		return address, fmt.Errorf("rcon: %w: %s", ErrInvalidAddress, err)
+	}
+
+	defaultPort := DefaultPort(game)
+	if defaultPort == 0 {
+		return address, fmt.Errorf("rcon: %w: %s", ErrMissingPort, address)
+	}
+
+	return net.JoinHostPort(host, strconv.Itoa(defaultPort)), nil
+}
+
+// hostWithoutPort returns the host of address which has no port, such as a
+// host name, IPv4 address or bracketed IPv6 address. It reports false if
+// address is malformed otherwise.
+func hostWithoutPort(address string) (string, bool) {
+	host := address
+	if strings.HasPrefix(host, "[") && strings.HasSuffix(host, "]") {
+		host = host[1 : len(host)-1]
+	} else if strings.Contains(host, ":") {
+		return "", false
+	}
+
+	if _, _, err := net.SplitHostPort(net.JoinHostPort(host, "0")); err != nil {
+		return "", false
+	}
+
+	return host, true
+}
+
+// checkPort returns ErrInvalidAddress if port of address is not a number from
+// 1 to 65535.
+func checkPort(address string, port string) error {
+	if n, err := strconv.ParseUint(port, 10, 16); err != nil || n == 0 {
+		return fmt.Errorf("rcon: %w: invalid port %q in address %s", ErrInvalidAddress, port, address)
+	}
+
+	return nil
+}
+
+// isConanExilesID reports whether id is the fixed response id of Conan Exiles
+// and the game type is set to GameConanExiles.
+func (c *Conn) isConanExilesID(id int32) bool {
+	return c.settings.gameType == GameConanExiles && id == conanExilesID
+}
+
+// isFixedResponseID reports whether id is a fixed response id which doesn't
+// match the request id: the one of Conan Exiles or -1, which Rust uses for
+// responses without data. The game type must be set.
+func (c *Conn) isFixedResponseID(id int32) bool {
+	return c.isConanExilesID(id) || (c.settings.gameType == GameRust && id == -1)
+}
@@ -0,0 +1,192 @@
+package rcon_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon/rcontest"
+)
+
+func TestDefaultPort(t *testing.T) {
+	tests := []struct {
+		game rcon.GameType
+		port int
+	}{
+		{rcon.GameUnknown, 0},
+		{rcon.GameSource, 27015},
+		{rcon.GameMinecraft, 25575},
+		{rcon.GameRust, 28016},
+		{rcon.GameProjectZomboid, 16261},
+		{rcon.GameConanExiles, 25575},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.game.String(), func(t *testing.T) {
+			if port := rcon.DefaultPort(tt.game); port != tt.port {
+				t.Errorf("got port %d, want %d", port, tt.port)
+			}
+		})
+	}
+}
+
+func TestSetGameType(t *testing.T) {
+	t.Run("missing port", func(t *testing.T) {
+		_, err := rcon.Dial("127.0.0.2", "password")
+		if !errors.Is(err, rcon.ErrMissingPort) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrMissingPort)
+		}
+	})
+
+	tests := []struct {
+		name            string
+		address         string
+		wantErrContains string
+	}{
+		{name: "default port", address: "127.0.0.2", wantErrContains: "127.0.0.2:25575"},
+		{name: "default port ipv6", address: "[::1]", wantErrContains: "[::1]:25575"},
+		{name: "default port hostname", address: "localhost", wantErrContains: ":25575"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := rcon.Dial(tt.address, "password", rcon.SetGameType(rcon.GameMinecraft))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErrContains) {
+				t.Errorf("got err %q, want to contain %q", err, tt.wantErrContains)
+			}
+
+			if errors.Is(err, rcon.ErrInvalidAddress) {
+				t.Errorf("got err %q, want dial error", err)
+			}
+		})
+	}
+}
+
+func TestDial_invalidAddress(t *testing.T) {
+	addresses := []string{
+		"127.0.0.2:",
+		"127.0.0.2:port",
+		"127.0.0.2:0",
+		"127.0.0.2:65536",
+		"127.0.0.2:1:2",
+		"[::1",
+		"[::1]:-1",
+		"::1",
+		"host]",
+	}
+
+	for _, address := range addresses {
+		t.Run(address, func(t *testing.T) {
+			_, err := rcon.Dial(address, "password", rcon.SetGameType(rcon.GameSource))
+			if !errors.Is(err, rcon.ErrInvalidAddress) {
+				t.Errorf("got err %q, want %q", err, rcon.ErrInvalidAddress)
+			}
+		})
+	}
+}
+
+func TestSetGameType_conanExiles(t *testing.T) {
+	server := rcontest.NewServer(
+		rcontest.SetAuthHandler(func(c *rcontest.Context) {
+			rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, 42, "").WriteTo(c.Conn())
+			rcon.NewPacket(rcon.SERVERDATA_AUTH_RESPONSE, 42, "").WriteTo(c.Conn())
+		}),
+		rcontest.SetCommandHandler(func(c *rcontest.Context) {
+			rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, 42, "Server: "+c.Request().Body()).WriteTo(c.Conn())
+		}),
+	)
+	defer server.Close()
+
+	t.Run("unknown game", func(t *testing.T) {
+		_, err := rcon.Dial(server.Addr(), "")
+		if !errors.Is(err, rcon.ErrInvalidPacketID) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrInvalidPacketID)
+		}
+	})
+
+	t.Run("conan exiles", func(t *testing.T) {
+		conn, err := rcon.Dial(server.Addr(), "", rcon.SetGameType(rcon.GameConanExiles))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		for _, command := range []string{"listplayers", "broadcast hello"} {
+			result, err := conn.Execute(command)
+			if err != nil {
+				t.Fatalf("got err %q, want %v", err, nil)
+			}
+
+			if result != "Server: "+command {
+				t.Errorf("got result %q, want %q", result, "Server: "+command)
+			}
+		}
+	})
+}
+
+func TestDetectGameType(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses map[string]string
+		game      rcon.GameType
+	}{
+		{
+			name: "source",
+			responses: map[string]string{
+				"status": "hostname: My Server\nudp/ip  : 0.0.0.0:27015\nmap     : de_dust2\n# userid name uniqueid connected ping loss state rate adr\n#end\n",
+			},
+			game: rcon.GameSource,
+		},
+		{
+			name: "rust",
+			responses: map[string]string{
+				"status": "hostname: My Server\nmap     : Procedural Map\n\nid name ping connected addr owner violation kicks\n",
+			},
+			game: rcon.GameRust,
+		},
+		{
+			name:      "minecraft",
+			responses: map[string]string{"list": "There are 0 of a max of 20 players online: "},
+			game:      rcon.GameMinecraft,
+		},
+		{
+			name:      "project zomboid",
+			responses: map[string]string{"players": "Players connected (0): "},
+			game:      rcon.GameProjectZomboid,
+		},
+		{
+			name: "unknown",
+			game: rcon.GameUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := rcontest.NewServer(rcontest.SetCommandHandler(func(c *rcontest.Context) {
+				response, ok := tt.responses[c.Request().Body()]
+				if !ok {
+					response = "Unknown command: " + c.Request().Body()
+				}
+
+				rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, response).WriteTo(c.Conn())
+			}))
+			defer server.Close()
+
+			conn, err := rcon.Dial(server.Addr(), "")
+			if err != nil {
+				t.Fatalf("got err %q, want %v", err, nil)
+			}
+			defer conn.Close()
+
+			game, err := rcon.DetectGameType(conn)
+			if err != nil {
+				t.Fatalf("got err %q, want %v", err, nil)
+			}
+
+			if game != tt.game {
+				t.Errorf("got game %s, want %s", game, tt.game)
+			}
+		})
+	}
+}
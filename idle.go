@@ -0,0 +1,55 @@
+package rcon
+
+import (
+	"math"
+	"time"
+)
+
+// LastUsed returns the time when the latest command has finished or when the
+// connection was authenticated, for example for bookkeeping of a pool. It is
+// safe to call concurrently with Execute.
+func (c *Conn) LastUsed() time.Time {
+	return time.Unix(0, c.stats.lastUsed.Load())
+}
+
+// markUsed sets the last used time to now.
+func (c *Conn) markUsed() {
+	c.stats.lastUsed.Store(time.Now().UnixNano())
+}
+
+// watchIdle starts the watchdog which closes the connection idle for longer
+// than SetMaxIdleTime. The watchdog is started once and stopped by Close.
+func (c *Conn) watchIdle() {
+	if c.settings.maxIdleTime <= 0 || c.idleTimer.Load() != nil {
+		return
+	}
+
+	// The timer is stored before it is started, so checkIdle always finds it.
+	timer := time.AfterFunc(math.MaxInt64, c.checkIdle)
+	c.idleTimer.Store(timer)
+	timer.Reset(c.settings.maxIdleTime)
+}
+
+// checkIdle closes the connection if it is idle for longer than SetMaxIdleTime
+// or schedules the next check. The connection isn't idle while a command holds
+// c.mu.
+func (c *Conn) checkIdle() {
+	timeout := c.settings.maxIdleTime
+
+	if c.mu.TryLock() {
+		idle := time.Since(c.LastUsed())
+		c.mu.Unlock()
+
+		if idle >= timeout {
+			_ = c.Close()
+
+			return
+		}
+
+		timeout -= idle
+	}
+
+	if timer := c.idleTimer.Load(); timer != nil && !c.IsClosed() {
+		timer.Reset(timeout)
+	}
+}
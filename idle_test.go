@@ -0,0 +1,51 @@
+package rcon_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon/rcontest"
+)
+
+func TestSetMaxIdleTime(t *testing.T) {
+	server := rcontest.NewServer(rcontest.SetCommandHandler(func(c *rcontest.Context) {
+		rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, "ok").WriteTo(c.Conn())
+	}))
+	defer server.Close()
+
+	conn, err := rcon.Dial(server.Addr(), "", rcon.SetMaxIdleTime(100*time.Millisecond))
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	t.Run("used", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			time.Sleep(60 * time.Millisecond)
+
+			before := time.Now()
+
+			if _, err := conn.Execute("status"); err != nil {
+				t.Fatalf("got err %q, want %v", err, nil)
+			}
+
+			if conn.LastUsed().Before(before) {
+				t.Errorf("got last used %s, want after %s", conn.LastUsed(), before)
+			}
+		}
+	})
+
+	t.Run("idle", func(t *testing.T) {
+		time.Sleep(250 * time.Millisecond)
+
+		if !conn.IsClosed() {
+			t.Fatal("got conn open, want closed")
+		}
+
+		if _, err := conn.Execute("status"); !errors.Is(err, rcon.ErrConnClosed) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrConnClosed)
+		}
+	})
+}
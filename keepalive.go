@@ -0,0 +1,28 @@
+package rcon
+
+import (
+	"fmt"
+	"net"
+	"time"
+)
+
+// setTCPKeepAlive enables TCP keepalive probes on conn. Connections other than
+// *net.TCPConn are left unchanged.
+func setTCPKeepAlive(conn net.Conn, idle time.Duration, interval time.Duration, count int) error {
+	tcpConn, ok := conn.(*net.TCPConn)
+	if !ok {
+		return nil
+	}
+
+	if err := tcpConn.SetKeepAlive(true); err != nil {
+		return fmt.Errorf("rcon: %w", err)
+	}
+
+	if idle != 0 {
+		if err := tcpConn.SetKeepAlivePeriod(idle); err != nil {
+			return fmt.Errorf("rcon: %w", err)
+		}
+	}
+
+	return setKeepAliveProbes(tcpConn, interval, count)
+}
@@ -0,0 +1,47 @@
+//go:build linux
+
+package rcon
+
+import (
+	"fmt"
+	"net"
+	"syscall"
+	"time"
+)
+
+// setKeepAliveProbes sets interval between keepalive probes and the number of
+// unacknowledged probes before the connection is considered dead.
+func setKeepAliveProbes(conn *net.TCPConn, interval time.Duration, count int) error {
+	if interval == 0 && count == 0 {
+		return nil
+	}
+
+	rawConn, err := conn.SyscallConn()
+	if err != nil {
+		return fmt.Errorf("rcon: %w", err)
+	}
+
+	var sockErr error
+
+	err = rawConn.Control(func(fd uintptr) {
+		if interval != 0 {
+			secs := int((interval + time.Second - 1) / time.Second)
+			if sockErr = syscall.SetsockoptInt(int(fd), syscall.IPPROTO_TCP, syscall.TCP_KEEPINTVL, secs); sockErr != nil {
+				return
+			}
+		}
+
+		if count != 0 {
+			sockErr = syscall.SetsockoptInt(int(fd), syscall.IPPROTO_TCP, syscall.TCP_KEEPCNT, count)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("rcon: %w", err)
+	}
+
+	if sockErr != nil {
+		return fmt.Errorf("rcon: %w", sockErr)
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+//go:build linux
+
+package rcon
+
+import (
+	"net"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestSetTCPKeepAlive(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if err := setTCPKeepAlive(conn, 30*time.Second, 5*time.Second, 3); err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	rawConn, err := conn.(*net.TCPConn).SyscallConn()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[int]int{
+		syscall.SO_KEEPALIVE:  1,
+		syscall.TCP_KEEPIDLE:  30,
+		syscall.TCP_KEEPINTVL: 5,
+		syscall.TCP_KEEPCNT:   3,
+	}
+
+	rawConn.Control(func(fd uintptr) {
+		for opt, value := range want {
+			level := syscall.IPPROTO_TCP
+			if opt == syscall.SO_KEEPALIVE {
+				level = syscall.SOL_SOCKET
+			}
+
+			got, err := syscall.GetsockoptInt(int(fd), level, opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got != value {
+				t.Errorf("got option %d value %d, want %d", opt, got, value)
+			}
+		}
+	})
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+
+package rcon
+
+import (
+	"net"
+	"time"
+)
+
+// setKeepAliveProbes is a no-op on platforms where the probe interval and
+// count are not settable per connection. The keepalive period set by
+// SetKeepAlivePeriod is used for both idle time and interval there.
+func setKeepAliveProbes(_ *net.TCPConn, _ time.Duration, _ int) error {
+	return nil
+}
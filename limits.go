@@ -0,0 +1,59 @@
+package rcon
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// Limits are the size limits of the server, for example to adapt commands and
+// SetMaxResponsePackets. Zero fields are not reported by the server.
+type Limits struct {
+	// MaxCommandLen is the maximum length of the command body.
+	MaxCommandLen int
+
+	// MaxPacketSize is the maximum body size of a response packet, so longer
+	// responses are split into multiple packets.
+	MaxPacketSize int
+}
+
+// limitsProbes contains functions which query limits of known game types.
+var limitsProbes = map[GameType]func(c *Conn) (Limits, error){
+	GameSource: sourceLimits,
+}
+
+var sourceMaxPacketSize = regexp.MustCompile(`"sv_rcon_maxpacketsize" = "(\d+)"`)
+
+// ServerLimits queries the size limits of the server of the game set by
+// SetGameType. For game types which don't expose the limits it returns an
+// error wrapping errors.ErrUnsupported. Supported game types are GameSource.
+func (c *Conn) ServerLimits() (Limits, error) {
+	probe, ok := limitsProbes[c.settings.gameType]
+	if !ok {
+		return Limits{}, fmt.Errorf("rcon: server limits of game %s: %w", c.settings.gameType, errors.ErrUnsupported)
+	}
+
+	return probe(c)
+}
+
+// sourceLimits reads sv_rcon_maxpacketsize cvar of Source servers. The command
+// length is limited by the protocol only.
+func sourceLimits(c *Conn) (Limits, error) {
+	response, err := c.Execute("sv_rcon_maxpacketsize")
+	if err != nil {
+		return Limits{}, err
+	}
+
+	match := sourceMaxPacketSize.FindStringSubmatch(response)
+	if match == nil {
+		return Limits{}, fmt.Errorf("rcon: %w: %q", ErrUnexpectedResponse, response)
+	}
+
+	size, err := strconv.Atoi(match[1])
+	if err != nil {
+		return Limits{}, fmt.Errorf("rcon: %w: %q", ErrUnexpectedResponse, response)
+	}
+
+	return Limits{MaxCommandLen: MaxCommandLen, MaxPacketSize: size}, nil
+}
@@ -0,0 +1,51 @@
+package rcon_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon/rcontest"
+)
+
+func TestConn_ServerLimits(t *testing.T) {
+	server := rcontest.NewServer(rcontest.SetCommandHandler(func(c *rcontest.Context) {
+		response := "Unknown command"
+		if c.Request().Body() == "sv_rcon_maxpacketsize" {
+			response = "\"sv_rcon_maxpacketsize\" = \"1024\" ( def. \"1024\" ) min. 100.000000 max. 4096.000000\n"
+		}
+
+		rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, response).WriteTo(c.Conn())
+	}))
+	defer server.Close()
+
+	t.Run("source", func(t *testing.T) {
+		conn, err := rcon.Dial(server.Addr(), "", rcon.SetGameType(rcon.GameSource))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		limits, err := conn.ServerLimits()
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		want := rcon.Limits{MaxCommandLen: rcon.MaxCommandLen, MaxPacketSize: 1024}
+		if limits != want {
+			t.Errorf("got limits %+v, want %+v", limits, want)
+		}
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		conn, err := rcon.Dial(server.Addr(), "", rcon.SetGameType(rcon.GameMinecraft))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		if _, err := conn.ServerLimits(); !errors.Is(err, errors.ErrUnsupported) {
+			t.Errorf("got err %q, want %q", err, errors.ErrUnsupported)
+		}
+	})
+}
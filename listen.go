@@ -0,0 +1,163 @@
+package rcon
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultListenBackoff is the wait before reconnecting a Listen stream if
+// SetAutoReconnect and SetReconnectBackoff have no backoff.
+const DefaultListenBackoff = time.Second
+
+// ListenEvent is an event of the stream returned by Listen.
+type ListenEvent struct {
+	// Packet is the packet pushed by the server. It is nil for other events.
+	Packet *Packet
+
+	// Reconnected is set when the stream has been resumed after the connection
+	// was broken with Err, so packets sent in the meantime are lost.
+	Reconnected bool
+
+	// Err is the error which ended the stream if Reconnected is not set.
+	Err error
+}
+
+// Listen streams packets pushed by the server, for example console logs,
+// until ctx is done or the connection breaks. The commands set by
+// SetListenSubscribeCommands are sent first and their responses are streamed
+// too. With SetListenAutoReconnect a broken connection is redialed and the
+// stream is resumed with a Reconnected event. The last event carries the
+// error which ended the stream unless ctx is done. The channel is closed
+// then. Commands wait until the stream has ended, because the connection is
+// dedicated to it.
+func (c *Conn) Listen(ctx context.Context) <-chan ListenEvent {
+	events := make(chan ListenEvent)
+
+	go func() {
+		defer close(events)
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		stop := c.watchContext(ctx)
+		defer stop()
+
+		send := func(event ListenEvent) bool {
+			select {
+			case events <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		err := c.subscribe(ctx)
+		if err == nil {
+			err = c.listen(ctx, send)
+		}
+
+		for ctx.Err() == nil && c.canRelisten(err) {
+			broken := err
+
+			if err = c.relisten(ctx); err != nil {
+				break
+			}
+
+			if !send(ListenEvent{Reconnected: true, Err: broken}) {
+				return
+			}
+
+			err = c.listen(ctx, send)
+		}
+
+		if ctx.Err() == nil {
+			send(ListenEvent{Err: err})
+		}
+	}()
+
+	return events
+}
+
+// listen reads packets without a deadline and passes them to send until
+// reading fails or send reports the stream is done.
+func (c *Conn) listen(ctx context.Context, send func(event ListenEvent) bool) error {
+	c.deadlineMu.Lock()
+	err := c.conn.SetReadDeadline(c.readDeadline)
+	c.deadlineMu.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	for {
+		packet, err := c.readPacket()
+		if err != nil {
+			return err
+		}
+
+		c.markUsed()
+
+		if !send(ListenEvent{Packet: packet}) {
+			return ctx.Err()
+		}
+	}
+}
+
+// subscribe sends the commands set by SetListenSubscribeCommands.
+func (c *Conn) subscribe(ctx context.Context) error {
+	if c.IsClosed() {
+		return ErrConnClosed
+	}
+
+	if !c.authenticated.Load() {
+		return ErrNotAuthenticated
+	}
+
+	for _, command := range c.settings.listenSubscribeCommands {
+		id, err := c.nextRequestID()
+		if err != nil {
+			return err
+		}
+
+		body, err := c.commandBody(command)
+		if err != nil {
+			return err
+		}
+
+		if err := c.write(ctx, SERVERDATA_EXECCOMMAND, id, body); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// canRelisten reports whether the stream broken with err is resumed.
+func (c *Conn) canRelisten(err error) bool {
+	return c.settings.listenAutoReconnect && c.address != "" && !c.IsClosed() && isConnError(err)
+}
+
+// relisten reconnects to the server after waiting for the backoff and sends
+// the subscribe commands again. Failed attempts are repeated while the
+// connection error can be retried.
+func (c *Conn) relisten(ctx context.Context) error {
+	for attempt := 0; ; attempt++ {
+		backoff := c.reconnectDelay(attempt)
+		if backoff == 0 {
+			backoff = DefaultListenBackoff
+		}
+
+		if err := sleepContext(ctx, backoff); err != nil {
+			return err
+		}
+
+		err := c.reconnect(ctx)
+		if err == nil {
+			err = c.subscribe(ctx)
+		}
+
+		if err == nil || !c.canRelisten(err) || ctx.Err() != nil {
+			return err
+		}
+	}
+}
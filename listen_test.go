@@ -0,0 +1,93 @@
+package rcon_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon/rcontest"
+)
+
+func TestConn_Listen(t *testing.T) {
+	var connections atomic.Int32
+
+	// The server pushes a pulse on sendpulse and breaks the first connection.
+	server := rcontest.NewServer(rcontest.SetCommandHandler(func(c *rcontest.Context) {
+		if c.Request().Body() != "sendpulse" {
+			rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, "ok").WriteTo(c.Conn())
+
+			return
+		}
+
+		n := connections.Add(1)
+		rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, -1, "pulse").WriteTo(c.Conn())
+
+		if n == 1 {
+			c.Conn().Close()
+		}
+	}))
+	defer server.Close()
+
+	t.Run("stream ends", func(t *testing.T) {
+		connections.Store(0)
+
+		conn, err := rcon.Dial(server.Addr(), "", rcon.SetListenSubscribeCommands([]string{"sendpulse"}))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		var events []rcon.ListenEvent
+		for event := range conn.Listen(context.Background()) {
+			events = append(events, event)
+		}
+
+		if len(events) != 2 || events[0].Packet.Body() != "pulse" || events[1].Err == nil {
+			t.Fatalf("got events %+v, want pulse and error", events)
+		}
+	})
+
+	t.Run("auto reconnect", func(t *testing.T) {
+		connections.Store(0)
+
+		conn, err := rcon.Dial(server.Addr(), "",
+			rcon.SetListenSubscribeCommands([]string{"sendpulse"}),
+			rcon.SetListenAutoReconnect(true),
+			rcon.SetAutoReconnect(0, 10*time.Millisecond),
+		)
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		events := conn.Listen(ctx)
+
+		if event := <-events; event.Packet == nil || event.Packet.Body() != "pulse" {
+			t.Errorf("got event %+v, want pulse", event)
+		}
+
+		if event := <-events; !event.Reconnected || event.Err == nil {
+			t.Errorf("got event %+v, want reconnected", event)
+		}
+
+		if event := <-events; event.Packet == nil || event.Packet.Body() != "pulse" {
+			t.Errorf("got event %+v, want pulse", event)
+		}
+
+		cancel()
+
+		for event := range events {
+			t.Errorf("got event %+v after cancel, want none", event)
+		}
+
+		// The connection is usable for commands after the stream.
+		if result, err := conn.Execute("status"); err != nil || result != "ok" {
+			t.Errorf("got result %q err %v, want %q %v", result, err, "ok", nil)
+		}
+	})
+}
@@ -0,0 +1,79 @@
+package rcon
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// MirrorPolicy decides when a command sent by MirrorConn succeeds.
+type MirrorPolicy int
+
+// Known mirror policies.
+const (
+	// MirrorAll succeeds if the command succeeds on both servers.
+	MirrorAll MirrorPolicy = iota
+
+	// MirrorAny succeeds if the command succeeds on at least one server.
+	MirrorAny
+)
+
+// MirrorConn sends every command to a primary and a standby server, for
+// example to keep mirrored servers of a cluster in the same state.
+type MirrorConn struct {
+	Primary *Conn
+	Standby *Conn
+	Policy  MirrorPolicy
+}
+
+// MirrorResult contains the results of a command on both servers.
+type MirrorResult struct {
+	Primary Result
+	Standby Result
+}
+
+// Execute executes command on both servers concurrently and waits for both
+// results. The returned error joins errors of failed servers if the command
+// failed according to the policy, otherwise it is nil.
+func (m *MirrorConn) Execute(command string) (MirrorResult, error) {
+	var (
+		result MirrorResult
+		wg     sync.WaitGroup
+	)
+
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+
+		result.Primary.Response, result.Primary.Err = m.Primary.Execute(command)
+	}()
+
+	go func() {
+		defer wg.Done()
+
+		result.Standby.Response, result.Standby.Err = m.Standby.Execute(command)
+	}()
+
+	wg.Wait()
+
+	if m.Policy == MirrorAny && (result.Primary.Err == nil || result.Standby.Err == nil) {
+		return result, nil
+	}
+
+	return result, errors.Join(mirrorErr("primary", result.Primary.Err), mirrorErr("standby", result.Standby.Err))
+}
+
+// Close closes both connections and returns the joined errors.
+func (m *MirrorConn) Close() error {
+	return errors.Join(m.Primary.Close(), m.Standby.Close())
+}
+
+// mirrorErr wraps err of the server named name. It returns nil if err is nil.
+func mirrorErr(name string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return fmt.Errorf("rcon: %s: %w", name, err)
+}
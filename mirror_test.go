@@ -0,0 +1,72 @@
+package rcon_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon/rcontest"
+)
+
+func TestMirrorConn_Execute(t *testing.T) {
+	primary := rcontest.NewServer(rcontest.SetCommandHandler(commandHandler))
+	defer primary.Close()
+
+	standby := rcontest.NewServer(rcontest.SetCommandHandler(commandHandler))
+	defer standby.Close()
+
+	dial := func(t *testing.T, policy rcon.MirrorPolicy) *rcon.MirrorConn {
+		t.Helper()
+
+		primaryConn, err := rcon.Dial(primary.Addr(), "")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		standbyConn, err := rcon.Dial(standby.Addr(), "")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		return &rcon.MirrorConn{Primary: primaryConn, Standby: standbyConn, Policy: policy}
+	}
+
+	t.Run("both succeed", func(t *testing.T) {
+		mirror := dial(t, rcon.MirrorAll)
+		defer mirror.Close()
+
+		result, err := mirror.Execute("help")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		for _, response := range []string{result.Primary.Response, result.Standby.Response} {
+			if response != "lorem ipsum dolor sit amet" {
+				t.Errorf("got response %q, want %q", response, "lorem ipsum dolor sit amet")
+			}
+		}
+	})
+
+	t.Run("standby failed", func(t *testing.T) {
+		for _, policy := range []rcon.MirrorPolicy{rcon.MirrorAll, rcon.MirrorAny} {
+			mirror := dial(t, policy)
+			mirror.Standby.Close()
+
+			result, err := mirror.Execute("help")
+
+			if !errors.Is(result.Standby.Err, rcon.ErrConnClosed) {
+				t.Errorf("got standby err %q, want %q", result.Standby.Err, rcon.ErrConnClosed)
+			}
+
+			if policy == rcon.MirrorAll && !errors.Is(err, rcon.ErrConnClosed) {
+				t.Errorf("got err %q, want %q", err, rcon.ErrConnClosed)
+			}
+
+			if policy == rcon.MirrorAny && err != nil {
+				t.Errorf("got err %q, want %v", err, nil)
+			}
+
+			mirror.Close()
+		}
+	})
+}
@@ -0,0 +1,92 @@
+package rcon
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// MultiConn executes commands on several servers at once, for example to run
+// a command across a fleet of servers.
+type MultiConn struct {
+	Conns []*Conn
+
+	// Concurrency limits the number of servers which execute the command at
+	// once. Zero means no limit.
+	Concurrency int
+
+	// Timeout limits the command on each server in addition to the connection
+	// deadline. Zero means no additional limit.
+	Timeout time.Duration
+}
+
+// Result is the response or error of a command on a single server.
+type Result struct {
+	Response string
+	Err      error
+}
+
+// ExecuteAll executes command on all servers concurrently and returns results
+// keyed by the server address, which is the dialed address or the remote
+// address of the connection created by NewConn. Connections must have
+// distinct addresses, otherwise only one of their results is kept.
+func (m *MultiConn) ExecuteAll(command string) map[string]Result {
+	results := make(map[string]Result, len(m.Conns))
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+
+	concurrency := m.Concurrency
+	if concurrency <= 0 {
+		concurrency = len(m.Conns)
+	}
+
+	slots := make(chan struct{}, concurrency)
+
+	for _, conn := range m.Conns {
+		wg.Add(1)
+
+		slots <- struct{}{}
+
+		go func(conn *Conn) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			response, err := m.execute(conn, command)
+
+			mu.Lock()
+			results[conn.observedAddress()] = Result{Response: response, Err: err}
+			mu.Unlock()
+		}(conn)
+	}
+
+	wg.Wait()
+
+	return results
+}
+
+// Close closes all connections and returns the joined errors.
+func (m *MultiConn) Close() error {
+	errs := make([]error, 0, len(m.Conns))
+
+	for _, conn := range m.Conns {
+		errs = append(errs, conn.Close())
+	}
+
+	return errors.Join(errs...)
+}
+
+// execute executes command on conn limited by m.Timeout.
+func (m *MultiConn) execute(conn *Conn, command string) (string, error) {
+	if m.Timeout <= 0 {
+		return conn.Execute(command)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), m.Timeout)
+	defer cancel()
+
+	return conn.ExecuteContext(ctx, command)
+}
@@ -0,0 +1,47 @@
+package rcon_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon/rcontest"
+)
+
+func TestMultiConn_ExecuteAll(t *testing.T) {
+	fast := rcontest.NewServer(rcontest.SetCommandHandler(commandHandler))
+	defer fast.Close()
+
+	slow := rcontest.NewServer(
+		rcontest.SetSettings(rcontest.Settings{CommandResponseDelay: 200 * time.Millisecond}),
+		rcontest.SetCommandHandler(commandHandler),
+	)
+	defer slow.Close()
+
+	multi := rcon.MultiConn{Concurrency: 1, Timeout: 50 * time.Millisecond}
+	defer multi.Close()
+
+	for _, server := range []*rcontest.Server{fast, slow} {
+		conn, err := rcon.Dial(server.Addr(), "")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		multi.Conns = append(multi.Conns, conn)
+	}
+
+	results := multi.ExecuteAll("help")
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want %d", len(results), 2)
+	}
+
+	if result := results[fast.Addr()]; result.Err != nil || result.Response != "lorem ipsum dolor sit amet" {
+		t.Errorf("got result %+v, want %q", result, "lorem ipsum dolor sit amet")
+	}
+
+	if result := results[slow.Addr()]; !errors.Is(result.Err, context.DeadlineExceeded) {
+		t.Errorf("got err %q, want %q", result.Err, context.DeadlineExceeded)
+	}
+}
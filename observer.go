@@ -0,0 +1,129 @@
+package rcon
+
+import (
+	"time"
+)
+
+// Observer receives events of Conn operations, for example to collect metrics.
+// Methods are called synchronously, so they must not block. Panics in them are
+// recovered unless SetRecoverCallbacks is disabled.
+type Observer interface {
+	// OnConnect is called when Dial has finished, successfully or not.
+	OnConnect(event ConnectEvent)
+
+	// OnCommand is called when a command has finished, successfully or not.
+	OnCommand(event CommandEvent)
+
+	// OnClose is called when the connection is closed by Close. It is called
+	// only for connections reported by OnConnect without error, so a failed
+	// Dial has an OnConnect event only.
+	OnClose(event CloseEvent)
+}
+
+// ConnectEvent describes a finished Dial. Duration is the total time, Timing
+// is its breakdown.
+type ConnectEvent struct {
+	Address  string
+	Duration time.Duration
+	Timing   Timing
+	Err      error
+}
+
+// Timing is the breakdown of Dial duration into TCP dial and auth handshake.
+// Auth is zero if the TCP dial failed.
+type Timing struct {
+	Dial time.Duration
+	Auth time.Duration
+}
+
+// CommandEvent describes a finished command. Tag is the tag passed to
+// ExecuteTagged or empty.
+type CommandEvent struct {
+	Address      string
+	Command      string
+	Tag          string
+	ResponseSize int
+	Duration     time.Duration
+	Err          error
+}
+
+// CloseEvent describes a closed connection.
+type CloseEvent struct {
+	Address string
+	Err     error
+}
+
+// observeConnect passes ConnectEvent to the observer if it is set. Auth is
+// started at authStart or it is zero if auth was not started.
+func (c *Conn) observeConnect(start time.Time, authStart time.Time, err error) {
+	c.connected.Store(err == nil)
+
+	if c.settings.observer == nil {
+		return
+	}
+
+	now := time.Now()
+	event := ConnectEvent{Address: c.observedAddress(), Duration: now.Sub(start), Err: err}
+
+	if authStart.IsZero() {
+		event.Timing.Dial = event.Duration
+	} else {
+		event.Timing.Dial = authStart.Sub(start)
+		event.Timing.Auth = now.Sub(authStart)
+	}
+
+	c.notify("Observer.OnConnect", func() { c.settings.observer.OnConnect(event) })
+}
+
+// observeCommand passes CommandEvent to the observer and the audit log if they
+// are set and reports the command if it is slow.
+func (c *Conn) observeCommand(command string, tag string, start time.Time, size int, err error) {
+	took := time.Since(start)
+
+	if c.settings.slowCommandLog != nil && c.settings.slowCommandThreshold > 0 && took > c.settings.slowCommandThreshold {
+		c.notify("slow command log", func() { c.settings.slowCommandLog(command, took) })
+	}
+
+	if c.settings.observer == nil && c.audit == nil {
+		return
+	}
+
+	event := CommandEvent{
+		Address:      c.observedAddress(),
+		Command:      command,
+		Tag:          tag,
+		ResponseSize: size,
+		Duration:     took,
+		Err:          err,
+	}
+
+	if c.settings.observer != nil {
+		c.notify("Observer.OnCommand", func() { c.settings.observer.OnCommand(event) })
+	}
+
+	if c.audit != nil {
+		c.audit.log(c.auditRecord(event))
+	}
+}
+
+// observeClose passes CloseEvent to the observer if it is set and the connect
+// was observed successfully.
+func (c *Conn) observeClose(err error) {
+	if c.settings.observer == nil || !c.connected.Load() {
+		return
+	}
+
+	event := CloseEvent{Address: c.observedAddress(), Err: err}
+
+	c.notify("Observer.OnClose", func() { c.settings.observer.OnClose(event) })
+}
+
+// observedAddress returns the dialed address or the remote address of the
+// connection created by NewConn.
+func (c *Conn) observedAddress() string {
+	if c.address != "" {
+		return c.address
+	}
+
+	return c.RemoteAddr().String()
+}
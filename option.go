@@ -1,22 +1,162 @@
 package rcon
 
-import "time"
+import (
+	"crypto/tls"
+	"encoding/binary"
+	"io"
+	"maps"
+	"time"
+)
 
 // Settings contains option to Conn.
 type Settings struct {
-	dialTimeout time.Duration
-	deadline    time.Duration
+	dialTimeout             time.Duration
+	deadline                time.Duration
+	writeInterceptor        PacketInterceptor
+	readInterceptor         PacketInterceptor
+	multiPacket             bool
+	sentinelID              int32
+	readIdleTimeout         time.Duration
+	rateLimit               float64
+	rateBurst               int
+	requestIDFunc           func() int32
+	keepAlive               bool
+	keepAliveIdle           time.Duration
+	keepAliveInterval       time.Duration
+	keepAliveCount          int
+	wireDump                io.Writer
+	readBufferHint          int
+	trimResponse            bool
+	reconnectAttempts       int
+	reconnectBackoff        time.Duration
+	gameType                GameType
+	maxResponsePackets      int
+	acceptedTypes           []int32
+	observer                Observer
+	banPatterns             []string
+	byteOrder               binary.ByteOrder
+	authOrder               AuthOrder
+	passwordFunc            func() (string, error)
+	connStateFunc           func(from ConnState, to ConnState)
+	appendNewline           bool
+	keepOpenOnAuthFail      bool
+	auditLog                io.Writer
+	skipEmptyKeepalives     bool
+	spoolThreshold          int
+	batchDeadline           time.Duration
+	transcoder              Transcoder
+	maxConnLifetime         time.Duration
+	paddingMode             PaddingMode
+	responseTerminator      string
+	slowCommandThreshold    time.Duration
+	slowCommandLog          func(command string, took time.Duration)
+	maxIdleTime             time.Duration
+	commandPipeline         []func(command string) (string, error)
+	responsePipeline        []func(response string) (string, error)
+	recoverCallbacks        bool
+	stripColorCodes         bool
+	minPacketSize           int32
+	tlsConfig               *tls.Config
+	clientCertFile          string
+	clientKeyFile           string
+	responsePrefixSkip      int
+	adaptiveTimeout         bool
+	errorPatterns           []string
+	aliases                 map[string]string
+	stripCommandEcho        bool
+	readBuffer              int
+	writeBuffer             int
+	responseValidator       func(command string, response string) error
+	expectTrailingStatus    bool
+	listenAutoReconnect     bool
+	listenSubscribeCommands []string
+	treatEmptyAsError       bool
+	reconnectBackoffMax     time.Duration
+	reconnectJitter         float64
+	resendDiscardedCommand  bool
+	validatorRetries        int
+	validatorBackoff        time.Duration
 }
 
 // DefaultSettings provides default deadline settings to Conn.
 var DefaultSettings = Settings{
-	dialTimeout: DefaultDialTimeout,
-	deadline:    DefaultDeadline,
+	dialTimeout:        DefaultDialTimeout,
+	deadline:           DefaultDeadline,
+	sentinelID:         DefaultSentinelID,
+	maxResponsePackets: DefaultMaxResponsePackets,
+	acceptedTypes:      []int32{SERVERDATA_RESPONSE_VALUE},
+	byteOrder:          binary.LittleEndian,
+	recoverCallbacks:   true,
+	errorPatterns:      DefaultErrorPatterns,
 }
 
 // Option allows to inject settings to Settings.
 type Option func(s *Settings)
 
+// newSettings returns DefaultSettings with options applied.
+func newSettings(options []Option) Settings {
+	settings := DefaultSettings
+
+	for _, option := range options {
+		option(&settings)
+	}
+
+	return settings
+}
+
+// PacketInterceptor is a function to inspect, rewrite or reject packets.
+// A returned packet replaces the original one, a returned error aborts
+// the operation.
+type PacketInterceptor func(p Packet) (Packet, error)
+
+// Transcoder converts commands from UTF-8 to the charset of the server and
+// responses back to UTF-8. The rconcharset module provides Transcoder for
+// encodings of golang.org/x/text.
+type Transcoder interface {
+	Encode(command string) (string, error)
+	Decode(response string) (string, error)
+}
+
+// AuthOrder is the order of packets which the server sends in response to
+// SERVERDATA_AUTH request.
+type AuthOrder int
+
+// Known auth orders.
+const (
+	// EmptyThenAuth is an empty SERVERDATA_RESPONSE_VALUE followed by
+	// SERVERDATA_AUTH_RESPONSE, as the protocol specifies. The empty packet
+	// is optional.
+	EmptyThenAuth AuthOrder = iota
+
+	// AuthThenEmpty is SERVERDATA_AUTH_RESPONSE followed by an empty
+	// SERVERDATA_RESPONSE_VALUE on successful authentication.
+	AuthThenEmpty
+
+	// AuthOnly is SERVERDATA_AUTH_RESPONSE without an empty packet.
+	AuthOnly
+)
+
+// PaddingMode is the check of two null bytes which terminate body of response
+// packets, one for the body string and one for the empty string.
+type PaddingMode int
+
+// Known padding modes.
+const (
+	// PaddingStrict requires the last two bytes of the body to be null bytes,
+	// which are removed. Otherwise ErrInvalidPacketPadding is returned.
+	PaddingStrict PaddingMode = iota
+
+	// PaddingLenient requires at least one null byte and cuts the body at the
+	// first null byte, so trailing garbage after the terminator is ignored.
+	// Note that it also cuts bodies with embedded null bytes. Bodies without
+	// null bytes return ErrInvalidPacketPadding.
+	PaddingLenient
+
+	// PaddingNone doesn't check the padding. Up to two trailing null bytes are
+	// removed, the rest of the body is kept as is.
+	PaddingNone
+)
+
 // SetDialTimeout injects dial Timeout to Settings.
 func SetDialTimeout(timeout time.Duration) Option {
 	return func(s *Settings) {
@@ -30,3 +170,582 @@ func SetDeadline(timeout time.Duration) Option {
 		s.deadline = timeout
 	}
 }
+
+// SetWriteInterceptor injects PacketInterceptor which is called before each
+// packet is written to the connection, including the auth packet. It is called
+// after command length checks, so it may produce a packet of any size.
+// The packet is written as is, use NewPacket to keep Size consistent with body.
+func SetWriteInterceptor(interceptor PacketInterceptor) Option {
+	return func(s *Settings) {
+		s.writeInterceptor = interceptor
+	}
+}
+
+// SetReadInterceptor injects PacketInterceptor which is called after each
+// packet is read from the connection and its size and padding are validated,
+// but before the packet is processed by auth or Execute.
+func SetReadInterceptor(interceptor PacketInterceptor) Option {
+	return func(s *Settings) {
+		s.readInterceptor = interceptor
+	}
+}
+
+// SetMultiPacket enables reading of responses which the server splits into
+// multiple packets. After each command an empty SERVERDATA_RESPONSE_VALUE
+// packet is sent. The server mirrors it after the last packet of the command
+// response, so all packets before the mirrored one are joined into a response.
+// The extra packet which SRCDS sends after the mirrored one is skipped.
+// If the server doesn't mirror it, set SetReadIdleTimeout, so the packets read
+// before the idle timeout are returned as the response. Without it the
+// deadline is exceeded and the error is returned.
+func SetMultiPacket(enabled bool) Option {
+	return func(s *Settings) {
+		s.multiPacket = enabled
+	}
+}
+
+// SetSentinelID injects packet id of the sentinel packet which is used to
+// detect the end of multi-packet response. The id must differ from the
+// command packet id. Default is DefaultSentinelID.
+func SetSentinelID(id int32) Option {
+	return func(s *Settings) {
+		s.sentinelID = id
+	}
+}
+
+// SetReadIdleTimeout injects the maximum time to wait for the next packet of
+// multi-packet response. When it is exceeded, the packets already read are
+// returned as the complete response. It allows to read responses from servers
+// which don't mirror the sentinel packet without waiting for the deadline.
+// Default is 0, which means waiting for the sentinel packet until deadline.
+func SetReadIdleTimeout(timeout time.Duration) Option {
+	return func(s *Settings) {
+		s.readIdleTimeout = timeout
+	}
+}
+
+// SetRateLimit limits commands sent to the server to rps commands per second
+// with bursts of at most burst commands. Execute waits until the command is
+// allowed, ExecuteContext returns ctx.Err() if ctx is done while waiting.
+// Default is 0, which means no limit.
+func SetRateLimit(rps float64, burst int) Option {
+	return func(s *Settings) {
+		s.rateLimit = rps
+		s.rateBurst = burst
+	}
+}
+
+// SetRequestIDFunc injects function which generates request id for each
+// command. The response is matched against the generated id. Generated ids
+// must be positive and differ from the sentinel id, otherwise Execute returns
+// ErrInvalidRequestID. Default is an incrementing counter starting from 1.
+func SetRequestIDFunc(f func() int32) Option {
+	return func(s *Settings) {
+		s.requestIDFunc = f
+	}
+}
+
+// SetTCPKeepAlive enables TCP keepalive probes of the operating system, so the
+// kernel detects a dead peer of a half-open connection. Idle is the time before
+// the first probe, interval is the time between probes and count is the number
+// of unacknowledged probes before the connection is closed. Zero value keeps
+// the system default. Interval and count are settable on Linux only, on other
+// platforms idle is used as the probe interval too. The option is ignored for
+// non-TCP connections.
+func SetTCPKeepAlive(idle time.Duration, interval time.Duration, count int) Option {
+	return func(s *Settings) {
+		s.keepAlive = true
+		s.keepAliveIdle = idle
+		s.keepAliveInterval = interval
+		s.keepAliveCount = count
+	}
+}
+
+// SetWireDump injects writer to which a hex and ASCII dump of raw bytes of
+// every outgoing and incoming packet is written. It is very verbose and is
+// intended for debugging of nonstandard server implementations only.
+func SetWireDump(w io.Writer) Option {
+	return func(s *Settings) {
+		s.wireDump = w
+	}
+}
+
+// SetReadBufferHint injects the expected size of multi-packet response in
+// bytes. The reassembly buffer is preallocated to n bytes, so a response of up
+// to n bytes is held in a single allocation instead of the intermediate copies
+// of a growing buffer. Default is 0, which means grow as needed. Negative n is
+// treated as 0.
+func SetReadBufferHint(n int) Option {
+	return func(s *Settings) {
+		s.readBufferHint = max(n, 0)
+	}
+}
+
+// SetTrimResponse enables stripping of trailing whitespace and null bytes from
+// responses returned by Execute. ExecuteBytes always returns raw response.
+// Default is false.
+func SetTrimResponse(enabled bool) Option {
+	return func(s *Settings) {
+		s.trimResponse = enabled
+	}
+}
+
+// SetAutoReconnect enables reconnecting to the server when a command fails
+// because the connection is broken. Up to attempts reconnects are made, each
+// after waiting for backoff, and the command is sent again after a successful
+// reconnect. With ExecuteContext the waits and reconnects are bounded by ctx,
+// so they never exceed the ctx deadline. Default is 0, which means no
+// reconnects.
+func SetAutoReconnect(attempts int, backoff time.Duration) Option {
+	return func(s *Settings) {
+		s.reconnectAttempts = attempts
+		s.reconnectBackoff = backoff
+	}
+}
+
+// SetGameType injects the game type of the server. Dial uses the default port
+// of the game if address has no port, GameConanExiles also enables its
+// response id quirk. Default is GameUnknown.
+func SetGameType(game GameType) Option {
+	return func(s *Settings) {
+		s.gameType = game
+	}
+}
+
+// SetMaxResponsePackets injects the maximum number of packets of multi-packet
+// response. If the server sends more packets before the sentinel, Execute
+// returns ErrTooManyPackets with the packets read so far. It protects against
+// servers which never mirror the sentinel. Default is
+// DefaultMaxResponsePackets, 0 means no limit.
+func SetMaxResponsePackets(n int) Option {
+	return func(s *Settings) {
+		s.maxResponsePackets = n
+	}
+}
+
+// SetAcceptedResponseTypes injects packet types which are valid responses to
+// commands. A packet of another type fails Execute with ErrInvalidResponseType.
+// Packets of type 4 are skipped as a known Rust quirk unless type 4 is listed.
+// The mirrored sentinel of multi-packet response has SERVERDATA_RESPONSE_VALUE
+// type, so it must be listed with SetMultiPacket. Default is
+// SERVERDATA_RESPONSE_VALUE and packets of type 4 are skipped. Without types
+// any type is accepted.
+func SetAcceptedResponseTypes(types ...int32) Option {
+	return func(s *Settings) {
+		s.acceptedTypes = types
+	}
+}
+
+// SetObserver injects Observer which receives events of Dial, commands and
+// Close. Default is nil, which means no events.
+func SetObserver(observer Observer) Option {
+	return func(s *Settings) {
+		s.observer = observer
+	}
+}
+
+// SetBanPatterns injects messages which servers send to banned clients. If a
+// command response contains any of them, ErrBanned is returned, so the caller
+// can back off instead of retrying. Patterns are matched case-insensitively.
+// The auth response and the last packet before a disconnect are always checked
+// against BanPatterns of the game type and patterns. Command responses aren't
+// checked by default, because acknowledgments of ban or kick commands and ban
+// lists contain the same words.
+func SetBanPatterns(patterns []string) Option {
+	return func(s *Settings) {
+		s.banPatterns = patterns
+	}
+}
+
+// SetByteOrder injects byte order of packet header fields for nonstandard
+// servers. Default is binary.LittleEndian as the protocol specifies, nil
+// restores it.
+func SetByteOrder(order binary.ByteOrder) Option {
+	return func(s *Settings) {
+		if order == nil {
+			order = binary.LittleEndian
+		}
+
+		s.byteOrder = order
+	}
+}
+
+// SetAuthOrder injects the order of packets which the server sends in response
+// to SERVERDATA_AUTH request. Default is EmptyThenAuth.
+func SetAuthOrder(order AuthOrder) Option {
+	return func(s *Settings) {
+		s.authOrder = order
+	}
+}
+
+// SetConnStateFunc injects function which is called on each state transition
+// of the connection in Dial, Execute and Close. It is called synchronously, so
+// it must not block. Default is nil.
+func SetConnStateFunc(f func(from ConnState, to ConnState)) Option {
+	return func(s *Settings) {
+		s.connStateFunc = f
+	}
+}
+
+// SetAppendNewline enables appending of a newline to each command before it is
+// sent. The protocol doesn't require it and none of the supported games need
+// it, but some nonstandard server implementations return empty responses to
+// commands without a trailing newline. The newline counts against
+// MaxCommandLen. Default is false.
+func SetAppendNewline(enabled bool) Option {
+	return func(s *Settings) {
+		s.appendNewline = enabled
+	}
+}
+
+// SetKeepOpenOnAuthFail disables closing of the connection when authorization
+// fails, so Dial returns the open connection with the error and the following
+// server data can be inspected via Raw. The caller then owns closing the
+// connection. Default is false.
+func SetKeepOpenOnAuthFail(enabled bool) Option {
+	return func(s *Settings) {
+		s.keepOpenOnAuthFail = enabled
+	}
+}
+
+// SetAuditLog injects writer of the audit log, which receives AuditRecord of
+// each command as a JSON line. Records are written in a separate goroutine, so
+// the log never stalls commands: write errors are ignored and records are
+// dropped if the writer falls behind. Close waits for queued records to be
+// written. Default is nil.
+func SetAuditLog(w io.Writer) Option {
+	return func(s *Settings) {
+		s.auditLog = w
+	}
+}
+
+// SetSkipEmptyKeepalives enables skipping of empty SERVERDATA_RESPONSE_VALUE
+// packets with ids other than the request id, which some servers periodically
+// send as keepalives between commands. Default is false.
+func SetSkipEmptyKeepalives(enabled bool) Option {
+	return func(s *Settings) {
+		s.skipEmptyKeepalives = enabled
+	}
+}
+
+// SetSpoolThreshold injects size in bytes above which ExecuteStream spools the
+// response to a temp file instead of memory, which keeps memory bounded for
+// very large multi-packet responses such as log dumps. Default is 0, responses
+// are kept in memory.
+func SetSpoolThreshold(n int) Option {
+	return func(s *Settings) {
+		s.spoolThreshold = n
+	}
+}
+
+// SetBatchDeadline injects the total time limit of batch methods RunScript and
+// Broadcast, which includes auto-reconnect retries of all commands. When it is
+// exceeded, the running command is interrupted, the remaining commands are not
+// sent and the error wraps context.DeadlineExceeded. Like with ExecuteContext,
+// the interrupted command leaves a rest of the response unread. Default is 0,
+// batches are not limited.
+func SetBatchDeadline(d time.Duration) Option {
+	return func(s *Settings) {
+		s.batchDeadline = d
+	}
+}
+
+// SetTranscoder injects Transcoder of commands and responses for servers which
+// don't use UTF-8. Encoded commands count against MaxCommandLen. Responses of
+// Execute and ExecutePipeline are decoded as a whole, ExecuteFunc decodes each
+// packet. Raw methods such as ExecuteBytes are not affected. Default is nil.
+func SetTranscoder(transcoder Transcoder) Option {
+	return func(s *Settings) {
+		s.transcoder = transcoder
+	}
+}
+
+// SetMaxConnLifetime injects the maximum age of the connection, for example to
+// recycle connections of a pool. Commands on an older connection return
+// ErrConnExpired, or redial it transparently with SetAutoReconnect. Default is
+// 0, the connection never expires.
+func SetMaxConnLifetime(d time.Duration) Option {
+	return func(s *Settings) {
+		s.maxConnLifetime = d
+	}
+}
+
+// SetMaxIdleTime injects the maximum time without commands after which a
+// background watchdog closes the connection, because idle connections of a
+// pool are more likely dead. Commands after that return ErrConnClosed. See
+// Conn.LastUsed. Default is 0, the connection is never closed for idleness.
+func SetMaxIdleTime(d time.Duration) Option {
+	return func(s *Settings) {
+		s.maxIdleTime = d
+	}
+}
+
+// SetPaddingMode injects the check of response packet padding for servers which
+// don't terminate bodies with exactly two null bytes. Default is PaddingStrict.
+func SetPaddingMode(mode PaddingMode) Option {
+	return func(s *Settings) {
+		s.paddingMode = mode
+	}
+}
+
+// SetResponseTerminator injects the marker which some servers append to the end
+// of multi-packet responses. With the marker set, the sentinel packet is not
+// sent and Execute stops reading when the marker appears in the reassembled
+// body, which is returned without it. ExecuteFunc and ExecuteStream only find
+// the marker within a single packet. Without the marker in the response, the
+// read idle timeout or the deadline ends it. ExecutePipeline still uses the
+// sentinel. Default is empty, the sentinel ends the response.
+func SetResponseTerminator(marker string) Option {
+	return func(s *Settings) {
+		s.responseTerminator = marker
+	}
+}
+
+// SetSlowCommandThreshold injects the function which is called with commands
+// taking longer than d, for example to log them. It is called synchronously
+// after the command has finished, successfully or not. Default is 0 and nil,
+// which means slow commands are not reported.
+func SetSlowCommandThreshold(d time.Duration, log func(command string, took time.Duration)) Option {
+	return func(s *Settings) {
+		s.slowCommandThreshold = d
+		s.slowCommandLog = log
+	}
+}
+
+// SetCommandPipeline injects functions which transform every command in the
+// given order before the length check and write, for example PrefixCommand
+// and AppendNewline. The first error aborts the command and is returned
+// wrapped. The functions run once per command after SetAliases expansion and
+// before SetAppendNewline and SetTranscoder. Default is empty, commands are
+// sent as given.
+func SetCommandPipeline(funcs ...func(command string) (string, error)) Option {
+	return func(s *Settings) {
+		s.commandPipeline = funcs
+	}
+}
+
+// SetResponsePipeline injects functions which transform every response of
+// Execute and ExecutePipeline in the given order, for example TrimResponse and
+// StripPrefix. The first error aborts the transformation and is returned
+// wrapped with the response transformed so far. The functions run after
+// SetTranscoder and SetTrimResponse. ExecuteBytes and other raw methods
+// bypass the pipeline. Default is empty, responses are returned as read.
+func SetResponsePipeline(funcs ...func(response string) (string, error)) Option {
+	return func(s *Settings) {
+		s.responsePipeline = funcs
+	}
+}
+
+// SetRecoverCallbacks enables recovering from panics in user callbacks, such
+// as Observer, the conn state function and packet interceptors, which may also
+// run on the goroutine of the idle watchdog. A panic in an interceptor fails
+// the operation with ErrCallbackPanic, a panic in other callbacks is logged
+// with the standard logger. Default is true.
+func SetRecoverCallbacks(enabled bool) Option {
+	return func(s *Settings) {
+		s.recoverCallbacks = enabled
+	}
+}
+
+// SetStripColorCodes enables removing of § formatting codes, which Minecraft
+// puts into responses, from responses of Execute and ExecutePipeline. Codes
+// are removed before SetTrimResponse and SetResponsePipeline are applied.
+// The rconminecraft package can convert the codes for terminals instead.
+// Default is false.
+func SetStripColorCodes(enabled bool) Option {
+	return func(s *Settings) {
+		s.stripColorCodes = enabled
+	}
+}
+
+// SetMinPacketSize injects the minimum size of command packets, which is the
+// value of the packet size field. It is a compatibility workaround for rare
+// servers which drop smaller packets: shorter command bodies are padded with
+// null bytes before the terminating nulls, which servers ignore after the
+// command. The padding doesn't count against MaxCommandLen. Default is 0,
+// packets are not padded.
+func SetMinPacketSize(n int) Option {
+	return func(s *Settings) {
+		s.minPacketSize = int32(min(n, int(MaxPacketSize)))
+	}
+}
+
+// SetTLSConfig injects the TLS config to connect over TLS, for example to
+// secured RCON gateways. Game servers don't support TLS. If ServerName is
+// empty, the host of the address is used. Default is nil, TLS is not used.
+func SetTLSConfig(config *tls.Config) Option {
+	return func(s *Settings) {
+		s.tlsConfig = config
+	}
+}
+
+// SetClientCert injects PEM encoded client certificate and key files, which
+// are presented to gateways which require mutual TLS. It enables TLS with the
+// config of SetTLSConfig or the default one. Dial returns an error if the files
+// are missing or invalid. Default is empty, no client certificate is sent.
+func SetClientCert(certFile string, keyFile string) Option {
+	return func(s *Settings) {
+		s.clientCertFile = certFile
+		s.clientKeyFile = keyFile
+	}
+}
+
+// SetResponsePrefixSkip injects the number of bytes which are discarded before
+// each response packet, for example a magic header of a nonstandard server.
+// It is a last resort for servers which can't be read otherwise, because the
+// bytes are not checked. Default is 0, nothing is discarded.
+func SetResponsePrefixSkip(n int) Option {
+	return func(s *Settings) {
+		s.responsePrefixSkip = n
+	}
+}
+
+// SetAdaptiveTimeout enables read timeouts of commands which are learned from
+// latencies of successful latest commands instead of the fixed deadline, so
+// variable latency servers don't time out while hung commands are still
+// caught. See Conn.CurrentTimeout. Default is false.
+func SetAdaptiveTimeout(enabled bool) Option {
+	return func(s *Settings) {
+		s.adaptiveTimeout = enabled
+	}
+}
+
+// SetErrorPatterns injects prefixes of responses which ExecuteChecked reports
+// as ErrCommandRejected. Patterns are matched case-insensitively. Default is
+// DefaultErrorPatterns, nil disables detection.
+func SetErrorPatterns(patterns []string) Option {
+	return func(s *Settings) {
+		s.errorPatterns = patterns
+	}
+}
+
+// SetAliases injects command aliases which are expanded before the command
+// pipeline, for example "wl add" for `addusertowhitelist "$1"`. An alias
+// matches the leading words of a command, the longest alias wins. Words after
+// the alias are arguments which replace $1 to $9 placeholders of the template,
+// $* is replaced by all arguments and $$ by "$". If the template has no
+// placeholders, the arguments are appended to it. Default is nil, commands are
+// sent as given.
+func SetAliases(aliases map[string]string) Option {
+	return func(s *Settings) {
+		s.aliases = maps.Clone(aliases)
+	}
+}
+
+// SetStripCommandEcho enables removing of the command from the first line of
+// responses of servers which echo commands back, optionally after a prompt
+// like "> ". Only the first line is checked, so the command is kept if it
+// appears later in the output. Default is false.
+func SetStripCommandEcho(enabled bool) Option {
+	return func(s *Settings) {
+		s.stripCommandEcho = enabled
+	}
+}
+
+// SetReadBuffer injects the size of the kernel receive buffer of the TCP
+// connection, for example to read large responses faster over high bandwidth
+// links. The operating system may round or clamp the size. The option is
+// ignored for non-TCP connections. Default is 0, the system default is kept.
+func SetReadBuffer(n int) Option {
+	return func(s *Settings) {
+		s.readBuffer = n
+	}
+}
+
+// SetWriteBuffer injects the size of the kernel send buffer of the TCP
+// connection like SetReadBuffer. Default is 0, the system default is kept.
+func SetWriteBuffer(n int) Option {
+	return func(s *Settings) {
+		s.writeBuffer = n
+	}
+}
+
+// SetResponseValidator injects the function which validates raw response
+// bodies before SetTranscoder and the response pipeline, for example against
+// a checksum included by the server. If it returns an error, the command
+// fails with ErrResponseCorrupt wrapping the error. See
+// SetResponseValidatorRetries to send the command again. Default is nil,
+// responses are not validated.
+func SetResponseValidator(validator func(command string, response string) error) Option {
+	return func(s *Settings) {
+		s.responseValidator = validator
+	}
+}
+
+// SetExpectTrailingStatus enables reading of the status packet which some
+// servers send after the response packet of every command. The status packet
+// is discarded, so it is not read as the response to the next command, and
+// is returned by LastPacket. It applies to responses without SetMultiPacket of
+// all Execute methods, ExecuteFunc, ExecuteStream and ExecutePipeline. Default
+// is false.
+func SetExpectTrailingStatus(enabled bool) Option {
+	return func(s *Settings) {
+		s.expectTrailingStatus = enabled
+	}
+}
+
+// SetListenAutoReconnect enables resuming of the Listen stream after the
+// connection is broken. The connection is redialed after the backoff of
+// SetAutoReconnect or DefaultListenBackoff until it succeeds or ctx is done.
+// Default is false, the stream ends with the connection.
+func SetListenAutoReconnect(enabled bool) Option {
+	return func(s *Settings) {
+		s.listenAutoReconnect = enabled
+	}
+}
+
+// SetListenSubscribeCommands injects commands which Listen sends when the
+// stream starts and after each reconnect, for example "sendpulse" of Project
+// Zomboid. Default is empty.
+func SetListenSubscribeCommands(commands []string) Option {
+	return func(s *Settings) {
+		s.listenSubscribeCommands = commands
+	}
+}
+
+// SetTreatEmptyAsError enables returning ErrEmptyResponse for commands which
+// the server answers with an empty body, for example servers which silently
+// ignore unknown commands. Default is false, empty responses are successful.
+func SetTreatEmptyAsError(enabled bool) Option {
+	return func(s *Settings) {
+		s.treatEmptyAsError = enabled
+	}
+}
+
+// SetReconnectBackoff replaces the fixed backoff of SetAutoReconnect and
+// SetListenAutoReconnect with exponential backoff, so connections of a pool
+// don't reconnect to a recovering server at once. The wait starts at base,
+// doubles with each attempt up to maxBackoff and is reduced by a random part
+// of up to jitter, which is a fraction from 0 to 1 of the wait. Default is
+// the fixed backoff.
+func SetReconnectBackoff(base time.Duration, maxBackoff time.Duration, jitter float64) Option {
+	return func(s *Settings) {
+		s.reconnectBackoff = base
+		s.reconnectBackoffMax = maxBackoff
+		s.reconnectJitter = jitter
+	}
+}
+
+// SetResendDiscardedCommand enables sending the command of DialAndExecute once
+// more after auth if the response to the command sent before auth completed
+// times out, for servers which discard commands received before auth. A slow
+// server may have run the command and only not responded in time, so it may
+// run twice. Enable it only for idempotent commands. Default is false, the
+// timeout is returned.
+func SetResendDiscardedCommand(enabled bool) Option {
+	return func(s *Settings) {
+		s.resendDiscardedCommand = enabled
+	}
+}
+
+// SetResponseValidatorRetries enables sending the command again on the same
+// connection when the validator of SetResponseValidator rejects its response.
+// Up to attempts retries are made, each after waiting for backoff. The
+// attempts and backoff of SetAutoReconnect don't apply. Default is 0, which
+// means no retries.
+func SetResponseValidatorRetries(attempts int, backoff time.Duration) Option {
+	return func(s *Settings) {
+		s.validatorRetries = attempts
+		s.validatorBackoff = backoff
+	}
+}
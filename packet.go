@@ -60,13 +60,18 @@ type Packet struct {
 // calculate packet size from body length and 10 bytes for rcon headers
 // and termination strings.
 func NewPacket(packetType int32, packetID int32, body string) *Packet {
-	size := len([]byte(body)) + int(PacketHeaderSize+PacketPaddingSize)
+	return newPacket(packetType, packetID, []byte(body))
+}
+
+// newPacket works like NewPacket but takes ownership of body bytes.
+func newPacket(packetType int32, packetID int32, body []byte) *Packet {
+	size := len(body) + int(PacketHeaderSize+PacketPaddingSize)
 
 	return &Packet{
 		Size: int32(size),
 		Type: packetType,
 		ID:   packetID,
-		body: []byte(body),
+		body: body,
 	}
 }
 
@@ -75,13 +80,30 @@ func (packet *Packet) Body() string {
 	return string(packet.body)
 }
 
+// clone returns a copy of packet with its own body or nil if packet is nil.
+func (packet *Packet) clone() *Packet {
+	if packet == nil {
+		return nil
+	}
+
+	clone := *packet
+	clone.body = bytes.Clone(packet.body)
+
+	return &clone
+}
+
 // WriteTo implements io.WriterTo for write a packet to w.
 func (packet *Packet) WriteTo(w io.Writer) (int64, error) {
+	return packet.writeTo(w, binary.LittleEndian)
+}
+
+// writeTo writes a packet to w with header fields in order.
+func (packet *Packet) writeTo(w io.Writer, order binary.ByteOrder) (int64, error) {
 	buffer := bytes.NewBuffer(make([]byte, 0, packet.Size+4))
 
-	_ = binary.Write(buffer, binary.LittleEndian, packet.Size)
-	_ = binary.Write(buffer, binary.LittleEndian, packet.ID)
-	_ = binary.Write(buffer, binary.LittleEndian, packet.Type)
+	_ = binary.Write(buffer, order, packet.Size)
+	_ = binary.Write(buffer, order, packet.ID)
+	_ = binary.Write(buffer, order, packet.Type)
 
 	// Write command body, null terminated ASCII string and an empty ASCIIZ string.
 	buffer.Write(append(packet.body, 0x00, 0x00))
@@ -91,54 +113,107 @@ func (packet *Packet) WriteTo(w io.Writer) (int64, error) {
 
 // ReadFrom implements io.ReaderFrom for read a packet from r.
 func (packet *Packet) ReadFrom(r io.Reader) (int64, error) {
-	var n int64
-
-	if err := binary.Read(r, binary.LittleEndian, &packet.Size); err != nil {
-		return n, fmt.Errorf("rcon: read packet size: %w", err)
-	}
+	return packet.readFrom(r, binary.LittleEndian, PaddingStrict)
+}
 
-	n += 4
+// readFrom reads a packet with header fields in order from r and checks its
+// padding according to padding mode.
+func (packet *Packet) readFrom(r io.Reader, order binary.ByteOrder, padding PaddingMode) (int64, error) {
+	header, n, err := readHeader(r, order)
+	packet.Size, packet.ID, packet.Type = header.Size, header.ID, header.Type
 
-	if packet.Size < MinPacketSize {
+	// The size is checked first, so a too small packet is reported even if
+	// the rest of the header is missing.
+	if n >= 4 && packet.Size < MinPacketSize {
 		return n, ErrResponseTooSmall
 	}
 
-	if err := binary.Read(r, binary.LittleEndian, &packet.ID); err != nil {
-		return n, fmt.Errorf("rcon: read packet id: %w", err)
+	if err != nil {
+		return n, err
 	}
 
-	n += 4
+	// String can actually include null characters which is the case in
+	// response to a SERVERDATA_RESPONSE_VALUE packet.
+	body, err := readBody(r, packet.Size-PacketHeaderSize)
+	n += int64(len(body))
 
-	if err := binary.Read(r, binary.LittleEndian, &packet.Type); err != nil {
-		return n, fmt.Errorf("rcon: read packet type: %w", err)
+	if err != nil {
+		return n, fmt.Errorf("rcon: %w", err)
 	}
 
-	n += 4
+	packet.body = body
 
-	// String can actually include null characters which is the case in
-	// response to a SERVERDATA_RESPONSE_VALUE packet.
-	packet.body = make([]byte, packet.Size-PacketHeaderSize)
+	// Remove null terminated strings from response body.
+	if packet.body, err = trimPadding(body, padding); err != nil {
+		return n, err
+	}
 
-	var i int32
-	for i < packet.Size-PacketHeaderSize {
-		var m int
-		var err error
+	return n, nil
+}
 
-		if m, err = r.Read(packet.body[i:]); err != nil {
-			return n + int64(m) + int64(i), fmt.Errorf("rcon: %w", err)
+// PaddingError is returned when the body of a response packet is not padded
+// according to the padding mode. It wraps ErrInvalidPacketPadding.
+type PaddingError struct {
+	// Trailing contains the last bytes of the body which failed the check.
+	Trailing []byte
+}
+
+// Error returns the error message with the trailing bytes in hex.
+func (e *PaddingError) Error() string {
+	return fmt.Sprintf("rcon: %s: trailing bytes % x", ErrInvalidPacketPadding, e.Trailing)
+}
+
+// Unwrap returns ErrInvalidPacketPadding.
+func (e *PaddingError) Unwrap() error {
+	return ErrInvalidPacketPadding
+}
+
+// paddingError returns PaddingError with the trailing bytes of body which
+// should be the padding.
+func paddingError(body []byte) error {
+	trailing := body[max(len(body)-int(PacketPaddingSize), 0):]
+
+	return &PaddingError{Trailing: bytes.Clone(trailing)}
+}
+
+// trimPadding removes padding from body according to padding mode.
+func trimPadding(body []byte, padding PaddingMode) ([]byte, error) {
+	switch padding {
+	case PaddingLenient:
+		end := bytes.IndexByte(body, 0x00)
+		if end < 0 {
+			return body, paddingError(body)
 		}
 
-		i += int32(m)
-	}
+		return body[:end], nil
+	case PaddingNone:
+		for i := int32(0); i < PacketPaddingSize && bytes.HasSuffix(body, []byte{0x00}); i++ {
+			body = body[:len(body)-1]
+		}
 
-	n += int64(i)
+		return body, nil
+	default:
+		if !bytes.HasSuffix(body, []byte{0x00, 0x00}) {
+			return body, paddingError(body)
+		}
 
-	// Remove null terminated strings from response body.
-	if !bytes.Equal(packet.body[len(packet.body)-int(PacketPaddingSize):], []byte{0x00, 0x00}) {
-		return n, ErrInvalidPacketPadding
+		return body[:len(body)-int(PacketPaddingSize)], nil
 	}
+}
 
-	packet.body = packet.body[0 : len(packet.body)-int(PacketPaddingSize)]
+// readBody reads size bytes from r. The buffer grows as data arrives, so
+// a malicious size doesn't allocate memory before the server sends the body.
+// Negative size of malformed packet returns ErrResponseTooSmall.
+func readBody(r io.Reader, size int32) ([]byte, error) {
+	if size < 0 {
+		return nil, ErrResponseTooSmall
+	}
 
-	return n, nil
+	var buffer bytes.Buffer
+
+	buffer.Grow(int(min(size, MaxPacketSize)))
+
+	_, err := io.CopyN(&buffer, r, int64(size))
+
+	return buffer.Bytes(), err
 }
@@ -5,6 +5,7 @@ import (
 	"encoding/binary"
 	"errors"
 	"io"
+	"net"
 	"testing"
 )
 
@@ -98,8 +99,8 @@ func TestPacket_ReadFrom(t *testing.T) {
 
 		packetGot := new(Packet)
 		nGot, err := packetGot.ReadFrom(&buffer)
-		if !errors.Is(err, io.EOF) {
-			t.Fatalf("got %q, want %q", err, io.EOF)
+		if !errors.Is(err, ErrTruncatedHeader) || !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Fatalf("got %q, want %q", err, ErrTruncatedHeader)
 		}
 
 		if nGot != 4 {
@@ -114,8 +115,8 @@ func TestPacket_ReadFrom(t *testing.T) {
 
 		packetGot := new(Packet)
 		nGot, err := packetGot.ReadFrom(&buffer)
-		if !errors.Is(err, io.EOF) {
-			t.Fatalf("got %q, want %q", err, io.EOF)
+		if !errors.Is(err, ErrTruncatedHeader) || !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Fatalf("got %q, want %q", err, ErrTruncatedHeader)
 		}
 
 		if nGot != 8 {
@@ -157,3 +158,239 @@ func TestPacket_ReadFrom(t *testing.T) {
 		}
 	})
 }
+
+func TestSetByteOrder(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		packetWant := NewPacket(SERVERDATA_EXECCOMMAND, 42, "testdata")
+
+		var buffer bytes.Buffer
+		if _, err := packetWant.writeTo(&buffer, binary.BigEndian); err != nil {
+			t.Fatal(err)
+		}
+
+		if size := binary.BigEndian.Uint32(buffer.Bytes()); size != uint32(packetWant.Size) {
+			t.Errorf("got big endian size %d, want %d", size, packetWant.Size)
+		}
+
+		packetGot := new(Packet)
+		if _, err := packetGot.readFrom(&buffer, binary.BigEndian, PaddingStrict); err != nil {
+			t.Fatal(err)
+		}
+
+		if packetGot.Size != packetWant.Size || packetGot.ID != packetWant.ID ||
+			packetGot.Type != packetWant.Type || packetGot.Body() != packetWant.Body() {
+			t.Errorf("got packet %+v, want %+v", packetGot, packetWant)
+		}
+	})
+
+	t.Run("execute", func(t *testing.T) {
+		client, server := net.Pipe()
+		defer server.Close()
+
+		go func() {
+			request := new(Packet)
+
+			// Authenticate and response to the only command.
+			if _, err := request.readFrom(server, binary.BigEndian, PaddingStrict); err != nil {
+				return
+			}
+
+			_, _ = NewPacket(SERVERDATA_AUTH_RESPONSE, request.ID, "").writeTo(server, binary.BigEndian)
+
+			if _, err := request.readFrom(server, binary.BigEndian, PaddingStrict); err != nil {
+				return
+			}
+
+			_, _ = NewPacket(SERVERDATA_RESPONSE_VALUE, request.ID, "big endian").writeTo(server, binary.BigEndian)
+		}()
+
+		conn, err := NewConn(client, "", SetByteOrder(binary.BigEndian))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		result, err := conn.Execute("help")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if result != "big endian" {
+			t.Errorf("got result %q, want %q", result, "big endian")
+		}
+	})
+}
+
+func TestPaddingMode(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		mode    PaddingMode
+		want    string
+		wantErr error
+	}{
+		{name: "strict exact", body: "ok\x00\x00", mode: PaddingStrict, want: "ok"},
+		{name: "strict embedded null", body: "o\x00k\x00\x00", mode: PaddingStrict, want: "o\x00k"},
+		{name: "strict trailing garbage", body: "ok\x00\x00xx", mode: PaddingStrict, wantErr: ErrInvalidPacketPadding},
+		{name: "strict single null", body: "ok\x01\x00", mode: PaddingStrict, wantErr: ErrInvalidPacketPadding},
+		{name: "lenient exact", body: "ok\x00\x00", mode: PaddingLenient, want: "ok"},
+		{name: "lenient trailing garbage", body: "ok\x00\x00xx", mode: PaddingLenient, want: "ok"},
+		{name: "lenient single null", body: "ok\x00x", mode: PaddingLenient, want: "ok"},
+		{name: "lenient no null", body: "okxx", mode: PaddingLenient, wantErr: ErrInvalidPacketPadding},
+		{name: "none exact", body: "ok\x00\x00", mode: PaddingNone, want: "ok"},
+		{name: "none extra null", body: "ok\x00\x00\x00", mode: PaddingNone, want: "ok\x00"},
+		{name: "none no null", body: "okxx", mode: PaddingNone, want: "okxx"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buffer bytes.Buffer
+
+			_ = binary.Write(&buffer, binary.LittleEndian, PacketHeaderSize+int32(len(tt.body)))
+			_ = binary.Write(&buffer, binary.LittleEndian, int32(42))
+			_ = binary.Write(&buffer, binary.LittleEndian, SERVERDATA_RESPONSE_VALUE)
+			buffer.WriteString(tt.body)
+
+			packet := new(Packet)
+
+			_, err := packet.readFrom(&buffer, binary.LittleEndian, tt.mode)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got err %q, want %v", err, tt.wantErr)
+			}
+
+			if err == nil && packet.Body() != tt.want {
+				t.Errorf("got body %q, want %q", packet.Body(), tt.want)
+			}
+		})
+	}
+}
+
+func TestPaddingError(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		mode PaddingMode
+		want []byte
+	}{
+		{name: "single null", body: "ok\x00", mode: PaddingStrict, want: []byte{'k', 0x00}},
+		{name: "garbage", body: "ok\x00\x01", mode: PaddingStrict, want: []byte{0x00, 0x01}},
+		{name: "no null", body: "okxx", mode: PaddingLenient, want: []byte{'x', 'x'}},
+		{name: "empty", body: "", mode: PaddingStrict, want: []byte{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := trimPadding([]byte(tt.body), tt.mode)
+
+			var paddingErr *PaddingError
+			if !errors.As(err, &paddingErr) {
+				t.Fatalf("got err %q, want %T", err, paddingErr)
+			}
+
+			if !bytes.Equal(paddingErr.Trailing, tt.want) {
+				t.Errorf("got trailing % x, want % x", paddingErr.Trailing, tt.want)
+			}
+
+			if !errors.Is(err, ErrInvalidPacketPadding) {
+				t.Errorf("got err %q, want %q", err, ErrInvalidPacketPadding)
+			}
+		})
+	}
+}
+
+func TestReadBody(t *testing.T) {
+	t.Run("negative size", func(t *testing.T) {
+		_, err := readBody(bytes.NewReader(nil), -4)
+		if !errors.Is(err, ErrResponseTooSmall) {
+			t.Errorf("got err %q, want %q", err, ErrResponseTooSmall)
+		}
+	})
+
+	t.Run("short body", func(t *testing.T) {
+		_, err := readBody(bytes.NewReader([]byte{0x00}), 2)
+		if !errors.Is(err, io.EOF) {
+			t.Errorf("got err %q, want %q", err, io.EOF)
+		}
+	})
+}
+
+func FuzzPacketReadFrom(f *testing.F) {
+	var valid bytes.Buffer
+
+	_, _ = NewPacket(SERVERDATA_RESPONSE_VALUE, 42, "testdata").WriteTo(&valid)
+
+	f.Add(valid.Bytes())
+	f.Add([]byte{})
+	f.Add([]byte{0xff, 0xff, 0xff, 0x7f, 0x2a, 0x00, 0x00, 0x00})
+	f.Add([]byte{0xf6, 0xff, 0xff, 0xff})
+	f.Add([]byte{0x0a, 0x00, 0x00, 0x00, 0x2a, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		packet := new(Packet)
+
+		n, err := packet.ReadFrom(bytes.NewReader(data))
+		if n > int64(len(data)) {
+			t.Errorf("got read %d bytes, want at most %d", n, len(data))
+		}
+
+		if err != nil {
+			return
+		}
+
+		if packet.Size < MinPacketSize {
+			t.Errorf("got size %d, want at least %d", packet.Size, MinPacketSize)
+		}
+
+		if int32(len(packet.body)) != packet.Size-MinPacketSize {
+			t.Errorf("got body len %d, want %d", len(packet.body), packet.Size-MinPacketSize)
+		}
+
+		if n != int64(packet.Size)+4 {
+			t.Errorf("got read %d bytes, want %d", n, packet.Size+4)
+		}
+	})
+}
+
+func TestReadHeader(t *testing.T) {
+	t.Run("full header", func(t *testing.T) {
+		var buffer bytes.Buffer
+
+		NewPacket(SERVERDATA_RESPONSE_VALUE, 42, "").WriteTo(&buffer)
+
+		packet, _, err := readHeader(&buffer, binary.LittleEndian)
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if packet.Size != MinPacketSize || packet.ID != 42 || packet.Type != SERVERDATA_RESPONSE_VALUE {
+			t.Errorf("got header %d %d %d, want %d %d %d", packet.Size, packet.ID, packet.Type, MinPacketSize, 42, SERVERDATA_RESPONSE_VALUE)
+		}
+	})
+
+	t.Run("truncated header", func(t *testing.T) {
+		var buffer bytes.Buffer
+
+		NewPacket(SERVERDATA_RESPONSE_VALUE, 42, "").WriteTo(&buffer)
+		buffer.Truncate(6)
+
+		_, _, err := readHeader(&buffer, binary.LittleEndian)
+		if !errors.Is(err, ErrTruncatedHeader) {
+			t.Errorf("got err %q, want %q", err, ErrTruncatedHeader)
+		}
+
+		if !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("got err %q, want %q", err, io.ErrUnexpectedEOF)
+		}
+	})
+
+	t.Run("no header", func(t *testing.T) {
+		_, _, err := readHeader(bytes.NewReader(nil), binary.LittleEndian)
+		if !errors.Is(err, io.EOF) {
+			t.Errorf("got err %q, want %q", err, io.EOF)
+		}
+
+		if errors.Is(err, ErrTruncatedHeader) {
+			t.Errorf("got err %q, want not %q", err, ErrTruncatedHeader)
+		}
+	})
+}
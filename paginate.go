@@ -0,0 +1,50 @@
+package rcon
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// MaxPages is the maximum number of pages which ExecutePaginated fetches.
+const MaxPages = 100
+
+// ErrTooManyPages is returned by ExecutePaginated when the server has more
+// than MaxPages pages, for example because it always reports the next page.
+var ErrTooManyPages = errors.New("too many pages")
+
+// ExecutePaginated fetches all pages of paginated output, for example of a ban
+// list, by commands made from cmdTemplate such as "bans %s". The first page is
+// fetched with an empty token and trailing spaces of the command trimmed.
+// After each page hasMore reports whether there is a next page and its token.
+// It returns the pages fetched so far and the error of the first failed page
+// or ErrTooManyPages. The pagination is limited by SetBatchDeadline.
+func (c *Conn) ExecutePaginated(cmdTemplate string, hasMore func(page string) (bool, string)) ([]string, error) {
+	if err := checkTemplate(cmdTemplate); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := c.batchContext()
+	defer cancel()
+
+	command := strings.TrimRight(fmt.Sprintf(cmdTemplate, ""), " ")
+	pages := make([]string, 0, 1)
+
+	for len(pages) < MaxPages {
+		page, err := c.ExecuteContext(ctx, command)
+		if err != nil {
+			return pages, fmt.Errorf("rcon: page %d: %w", len(pages)+1, err)
+		}
+
+		pages = append(pages, page)
+
+		more, token := hasMore(page)
+		if !more {
+			return pages, nil
+		}
+
+		command = fmt.Sprintf(cmdTemplate, token)
+	}
+
+	return pages, fmt.Errorf("rcon: %w: more than %d", ErrTooManyPages, MaxPages)
+}
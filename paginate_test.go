@@ -0,0 +1,74 @@
+package rcon_test
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon/rcontest"
+)
+
+func TestConn_ExecutePaginated(t *testing.T) {
+	server := rcontest.NewServer(rcontest.SetCommandHandler(func(c *rcontest.Context) {
+		var response string
+
+		switch body := c.Request().Body(); body {
+		case "bans":
+			response = "page 1 of 3"
+		case "bans 2", "bans 3":
+			response = "page " + body[len("bans "):] + " of 3"
+		default:
+			response = "page 1 of 1000"
+		}
+
+		rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, response).WriteTo(c.Conn())
+	}))
+	defer server.Close()
+
+	conn, err := rcon.Dial(server.Addr(), "")
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	hasMore := func(page string) (bool, string) {
+		var current, total int
+
+		if _, err := fmt.Sscanf(page, "page %d of %d", &current, &total); err != nil {
+			return false, ""
+		}
+
+		return current < total, strconv.Itoa(current + 1)
+	}
+
+	t.Run("pages", func(t *testing.T) {
+		pages, err := conn.ExecutePaginated("bans %s", hasMore)
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		want := []string{"page 1 of 3", "page 2 of 3", "page 3 of 3"}
+		if fmt.Sprint(pages) != fmt.Sprint(want) {
+			t.Errorf("got pages %q, want %q", pages, want)
+		}
+	})
+
+	t.Run("too many pages", func(t *testing.T) {
+		pages, err := conn.ExecutePaginated("loop %s", hasMore)
+		if !errors.Is(err, rcon.ErrTooManyPages) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrTooManyPages)
+		}
+
+		if len(pages) != rcon.MaxPages {
+			t.Errorf("got %d pages, want %d", len(pages), rcon.MaxPages)
+		}
+	})
+
+	t.Run("invalid template", func(t *testing.T) {
+		if _, err := conn.ExecutePaginated("bans", hasMore); !errors.Is(err, rcon.ErrInvalidTemplate) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrInvalidTemplate)
+		}
+	})
+}
@@ -0,0 +1,58 @@
+package rcon
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// PasswordFromEnv resolves the password from the environment variable key when
+// connecting. The password passed to Dial is ignored then. Dial returns
+// ErrEmptyPassword if the variable is not set or empty.
+func PasswordFromEnv(key string) Option {
+	return func(s *Settings) {
+		s.passwordFunc = func() (string, error) {
+			password := os.Getenv(key)
+			if password == "" {
+				return "", fmt.Errorf("%w: environment variable %s", ErrEmptyPassword, key)
+			}
+
+			return password, nil
+		}
+	}
+}
+
+// PasswordFromFile resolves the password from the file at path when
+// connecting. Trailing line breaks are trimmed. The password passed to Dial is
+// ignored then. Dial returns ErrEmptyPassword if the file is empty.
+func PasswordFromFile(path string) Option {
+	return func(s *Settings) {
+		s.passwordFunc = func() (string, error) {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return "", err
+			}
+
+			password := strings.TrimRight(string(data), "\r\n")
+			if password == "" {
+				return "", fmt.Errorf("%w: file %s", ErrEmptyPassword, path)
+			}
+
+			return password, nil
+		}
+	}
+}
+
+// resolvePassword returns the password resolved by the settings or password.
+func resolvePassword(settings *Settings, password string) (string, error) {
+	if settings.passwordFunc == nil {
+		return password, nil
+	}
+
+	password, err := settings.passwordFunc()
+	if err != nil {
+		return "", fmt.Errorf("rcon: %w", err)
+	}
+
+	return password, nil
+}
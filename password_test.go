@@ -0,0 +1,75 @@
+package rcon_test
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon/rcontest"
+)
+
+func TestPasswordFromEnv(t *testing.T) {
+	server := rcontest.NewServer(rcontest.SetSettings(rcontest.Settings{Password: "password"}))
+	defer server.Close()
+
+	t.Run("success", func(t *testing.T) {
+		t.Setenv("TEST_RCON_PASSWORD", "password")
+
+		conn, err := rcon.Dial(server.Addr(), "", rcon.PasswordFromEnv("TEST_RCON_PASSWORD"))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		conn.Close()
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		t.Setenv("TEST_RCON_PASSWORD", "")
+
+		_, err := rcon.Dial(server.Addr(), "password", rcon.PasswordFromEnv("TEST_RCON_PASSWORD"))
+		if !errors.Is(err, rcon.ErrEmptyPassword) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrEmptyPassword)
+		}
+	})
+}
+
+func TestPasswordFromFile(t *testing.T) {
+	server := rcontest.NewServer(rcontest.SetSettings(rcontest.Settings{Password: "password"}))
+	defer server.Close()
+
+	dir := t.TempDir()
+
+	t.Run("success", func(t *testing.T) {
+		path := filepath.Join(dir, "password")
+		if err := os.WriteFile(path, []byte("password\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		conn, err := rcon.Dial(server.Addr(), "", rcon.PasswordFromFile(path))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		conn.Close()
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		path := filepath.Join(dir, "empty")
+		if err := os.WriteFile(path, []byte("\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := rcon.Dial(server.Addr(), "password", rcon.PasswordFromFile(path))
+		if !errors.Is(err, rcon.ErrEmptyPassword) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrEmptyPassword)
+		}
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		_, err := rcon.Dial(server.Addr(), "password", rcon.PasswordFromFile(filepath.Join(dir, "missing")))
+		if !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("got err %q, want %q", err, fs.ErrNotExist)
+		}
+	})
+}
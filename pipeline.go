@@ -0,0 +1,223 @@
+package rcon
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrPipelineUnsupported is returned by ExecutePipeline when responses can't be
+// matched to the pipelined commands.
+var ErrPipelineUnsupported = errors.New("pipeline unsupported")
+
+// ExecutePipeline sends all commands before reading any response and matches
+// the responses to the commands by request id. Responses are returned in the
+// order of commands. It saves round trips on high latency links, but works only
+// with servers which mirror request ids and don't wait for the client to read
+// a response before processing the next command. Servers which serialize RCON
+// respond in order, so the result is the same as from sequential Execute calls.
+// If a response has an unknown id, ErrInvalidPacketID is returned with the
+// responses read so far. The rest of the responses is left unread then, so the
+// caller should close the connection and fall back to Execute on a new one.
+//
+// Responses with fixed ids, which Conan Exiles sends for every command and
+// Rust for some commands such as say, are matched in send order instead,
+// so the game type must be set by SetGameType. Fragments with a fixed id
+// can't be told apart, so ExecutePipeline returns ErrPipelineUnsupported for
+// GameConanExiles with SetMultiPacket.
+func (c *Conn) ExecutePipeline(commands []string) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.settings.multiPacket && c.settings.gameType == GameConanExiles {
+		return nil, fmt.Errorf("rcon: %w: multi-packet responses of %s", ErrPipelineUnsupported, GameConanExiles)
+	}
+
+	start := time.Now()
+
+	c.lastPacket.Store(nil)
+	c.truncated = false
+
+	sent := make([]string, len(commands))
+	packetBodies := make([]string, len(commands))
+
+	for i, command := range commands {
+		var err error
+
+		sent[i], packetBodies[i], err = c.checkCommand(command)
+		if err != nil {
+			c.observeCommand(command, "", start, 0, err)
+
+			return nil, err
+		}
+	}
+
+	ctx, done := c.cancelable(context.Background())
+	defer done()
+
+	stop := c.watchContext(ctx)
+	defer stop()
+
+	c.setState(StateExecuting)
+
+	bodies, err := c.pipeline(ctx, packetBodies)
+	if err != nil {
+		err = contextErr(ctx, err)
+	}
+
+	if bodies == nil {
+		for _, command := range commands {
+			c.finishCommand(command, "", start, 0, err)
+		}
+
+		return nil, err
+	}
+
+	errs := []error{err}
+
+	responses := make([]string, len(commands))
+	for i := range bodies {
+		if err == nil {
+			errs = append(errs, c.finishCommand(commands[i], "", start, bodies[i].Len(), nil))
+		} else {
+			c.finishCommand(commands[i], "", start, bodies[i].Len(), err)
+		}
+
+		var processErr error
+
+		responses[i], processErr = c.processResponse(commands[i], sent[i], bodies[i].String())
+		errs = append(errs, processErr)
+	}
+
+	return responses, errors.Join(errs...)
+}
+
+// pipeline sends packetBodies of commands and reads their responses into bodies
+// by index.
+func (c *Conn) pipeline(ctx context.Context, packetBodies []string) ([]bytes.Buffer, error) {
+	if err := c.checkLifetime(ctx); err != nil {
+		return nil, err
+	}
+
+	order := make([]int32, len(packetBodies))
+	ids := make(map[int32]int, len(packetBodies))
+
+	for i := range packetBodies {
+		id, err := c.nextRequestID()
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := ids[id]; ok {
+			return nil, fmt.Errorf("rcon: %w: %d is not unique", ErrInvalidRequestID, id)
+		}
+
+		order[i] = id
+		ids[id] = i
+	}
+
+	for i, id := range order {
+		if err := c.limiter.wait(ctx); err != nil {
+			return nil, err
+		}
+
+		if err := c.write(ctx, SERVERDATA_EXECCOMMAND, id, packetBodies[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.settings.multiPacket {
+		// The sentinel is mirrored after responses to all commands.
+		if err := c.write(ctx, SERVERDATA_RESPONSE_VALUE, c.settings.sentinelID, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	bodies := make([]bytes.Buffer, len(packetBodies))
+
+	return bodies, c.readPipeline(ctx, order, ids, bodies)
+}
+
+// readPipeline reads responses to pipelined commands into bodies by index from
+// ids. Without multi-packet it reads a single packet per command, otherwise it
+// reads packets until the sentinel is mirrored or the read idle timeout.
+// Responses with the fixed ids of Rust and Conan Exiles are matched to the
+// first command in send order which has no response yet.
+func (c *Conn) readPipeline(ctx context.Context, order []int32, ids map[int32]int, bodies []bytes.Buffer) error {
+	// Packets are counted per response, the limit of SetMaxResponsePackets
+	// applies to each command like in Execute.
+	packets := make([]int, len(bodies))
+	pending := len(bodies)
+	next := 0
+
+	for pending > 0 || c.settings.multiPacket {
+		read := c.read
+		if c.settings.multiPacket && pending < len(bodies) {
+			read = c.readNext
+		}
+
+		// Request id is unknown, so the fixed ids of Rust and Conan Exiles
+		// are kept as is and matched below.
+		response, err := read(ctx, -1)
+		if err != nil {
+			// Like readFragments, the idle timeout ends the response if the
+			// server doesn't mirror the sentinel.
+			if c.settings.multiPacket && pending < len(bodies) && c.isIdleTimeout(ctx, err) {
+				return nil
+			}
+
+			return err
+		}
+
+		if c.settings.multiPacket && response.ID == c.settings.sentinelID {
+			return nil
+		}
+
+		i, ok := ids[response.ID]
+		if !ok && c.isFixedResponseID(response.ID) {
+			for next < len(order) && packets[next] > 0 {
+				next++
+			}
+
+			if next < len(order) {
+				i, ok = next, true
+				response.ID = order[next]
+			}
+		}
+
+		if !ok {
+			if c.isKeepalive(response, -1) {
+				continue
+			}
+
+			return ErrInvalidPacketID
+		}
+
+		packets[i]++
+		if c.settings.multiPacket && c.tooManyPackets(packets[i]) {
+			return ErrTooManyPackets
+		}
+
+		if !c.settings.multiPacket {
+			// Each command has exactly one response packet.
+			delete(ids, response.ID)
+		}
+
+		if packets[i] == 1 {
+			pending--
+		}
+
+		c.truncated = isTruncated(response)
+		bodies[i].Write(response.body)
+
+		if !c.settings.multiPacket && c.settings.expectTrailingStatus {
+			if err := c.readTrailingStatus(ctx); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,98 @@
+package rcon
+
+import (
+	"sync"
+	"time"
+)
+
+// PriorityConn serves commands of goroutines sharing Conn by priority, so
+// urgent commands don't wait behind routine polling. Commands with higher
+// priority are executed first, commands with equal priority in the order of
+// calls. Without Aging, low priority commands may starve while higher ones
+// keep coming.
+type PriorityConn struct {
+	Conn *Conn
+
+	// Aging raises the priority of a waiting command by one for each Aging it
+	// waits, so low priority commands are executed eventually. Zero means no
+	// aging.
+	Aging time.Duration
+
+	mu      sync.Mutex
+	busy    bool
+	waiters []*priorityWaiter
+}
+
+// priorityWaiter is a command waiting for its turn.
+type priorityWaiter struct {
+	priority int
+	since    time.Time
+	ready    chan struct{}
+}
+
+// Execute executes command with priority 0.
+func (p *PriorityConn) Execute(command string) (string, error) {
+	return p.ExecutePriority(command, 0)
+}
+
+// ExecutePriority waits until no command with higher priority is waiting and
+// executes command on Conn.
+func (p *PriorityConn) ExecutePriority(command string, priority int) (string, error) {
+	p.acquire(priority)
+	defer p.release()
+
+	return p.Conn.Execute(command)
+}
+
+// acquire waits for the turn of a command with priority.
+func (p *PriorityConn) acquire(priority int) {
+	p.mu.Lock()
+
+	if !p.busy {
+		p.busy = true
+		p.mu.Unlock()
+
+		return
+	}
+
+	waiter := &priorityWaiter{priority: priority, since: time.Now(), ready: make(chan struct{})}
+	p.waiters = append(p.waiters, waiter)
+	p.mu.Unlock()
+
+	<-waiter.ready
+}
+
+// release passes the turn to the waiting command with the highest priority.
+func (p *PriorityConn) release() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.waiters) == 0 {
+		p.busy = false
+
+		return
+	}
+
+	now := time.Now()
+	next := 0
+
+	for i := 1; i < len(p.waiters); i++ {
+		if p.effectivePriority(p.waiters[i], now) > p.effectivePriority(p.waiters[next], now) {
+			next = i
+		}
+	}
+
+	waiter := p.waiters[next]
+	p.waiters = append(p.waiters[:next], p.waiters[next+1:]...)
+
+	close(waiter.ready)
+}
+
+// effectivePriority returns the priority of waiter raised by aging.
+func (p *PriorityConn) effectivePriority(waiter *priorityWaiter, now time.Time) int {
+	if p.Aging <= 0 {
+		return waiter.priority
+	}
+
+	return waiter.priority + int(now.Sub(waiter.since)/p.Aging)
+}
@@ -0,0 +1,80 @@
+package rcon_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon/rcontest"
+)
+
+func TestPriorityConn_ExecutePriority(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		received []string
+	)
+
+	server := rcontest.NewServer(rcontest.SetCommandHandler(func(c *rcontest.Context) {
+		if c.Request().Body() == "slow" {
+			time.Sleep(100 * time.Millisecond)
+		}
+
+		mu.Lock()
+		received = append(received, c.Request().Body())
+		mu.Unlock()
+
+		rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, "ok").WriteTo(c.Conn())
+	}))
+	defer server.Close()
+
+	conn, err := rcon.Dial(server.Addr(), "")
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	tests := []struct {
+		name  string
+		aging time.Duration
+		want  []string
+	}{
+		{name: "priority", want: []string{"slow", "high", "low"}},
+		{name: "aging", aging: 500 * time.Microsecond, want: []string{"slow", "low", "high"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received = nil
+			priority := rcon.PriorityConn{Conn: conn, Aging: tt.aging}
+
+			var wg sync.WaitGroup
+
+			for _, command := range []struct {
+				body     string
+				priority int
+			}{{"slow", 0}, {"low", 0}, {"high", 10}} {
+				wg.Add(1)
+
+				go func(body string, p int) {
+					defer wg.Done()
+
+					if _, err := priority.ExecutePriority(body, p); err != nil {
+						t.Errorf("got err %q, want %v", err, nil)
+					}
+				}(command.body, command.priority)
+
+				time.Sleep(20 * time.Millisecond)
+			}
+
+			wg.Wait()
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if len(received) != len(tt.want) || received[1] != tt.want[1] || received[2] != tt.want[2] {
+				t.Errorf("got commands %q, want %q", received, tt.want)
+			}
+		})
+	}
+}
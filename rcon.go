@@ -3,11 +3,21 @@
 package rcon
 
 import (
+	"bytes"
+	"context"
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"io"
+	"math"
 	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
+
+	"github.com/gorcon/rcon/rconminecraft"
 )
 
 const (
@@ -49,12 +59,27 @@ const (
 	SERVERDATA_EXECCOMMAND int32 = 2
 
 	// SERVERDATA_EXECCOMMAND_ID is any positive integer, chosen by the client
-	// (will be mirrored back in the server's response).
+	// (will be mirrored back in the server's response). Execute generates
+	// request IDs by SetRequestIDFunc or by the incrementing counter.
 	SERVERDATA_EXECCOMMAND_ID int32 = 0
+
+	// DefaultMaxResponsePackets is the default maximum number of packets of
+	// multi-packet response.
+	DefaultMaxResponsePackets = 256
+
+	// DefaultSentinelID is the default packet id of the empty
+	// SERVERDATA_RESPONSE_VALUE packet which is sent after the command to
+	// detect the end of multi-packet response.
+	DefaultSentinelID int32 = 100
 )
 
+// trimCutset contains trailing characters which are stripped from responses
+// if SetTrimResponse is enabled.
+const trimCutset = " \t\r\n\x00"
+
 var (
 	// ErrAuthNotRCON is returned when got auth response with negative size.
+	// It is returned wrapped together with ErrResponseTooSmall.
 	ErrAuthNotRCON = errors.New("response from not rcon server")
 
 	// ErrInvalidAuthResponse is returned when we didn't get an auth packet
@@ -70,14 +95,27 @@ var (
 	// was not mirrored back from request.
 	ErrInvalidPacketID = errors.New("response for another request")
 
+	// ErrInvalidRequestID is returned when the generated request id is not
+	// a positive integer or is equal to the sentinel id.
+	ErrInvalidRequestID = errors.New("invalid request id")
+
+	// ErrInvalidResponseType is returned when the response packet type is not
+	// accepted by SetAcceptedResponseTypes.
+	ErrInvalidResponseType = errors.New("invalid response packet type")
+
 	// ErrInvalidPacketPadding is returned when the bytes after type field from
-	// response is not equal to null-terminated ASCII strings.
+	// response is not equal to null-terminated ASCII strings. It is wrapped
+	// by PaddingError with the offending bytes.
 	ErrInvalidPacketPadding = errors.New("invalid response padding")
 
 	// ErrResponseTooSmall is returned when the server response is smaller
 	// than 10 bytes.
 	ErrResponseTooSmall = errors.New("response too small")
 
+	// ErrTruncatedHeader is returned when the connection ends in the middle of
+	// the packet header. It is wrapped together with io.ErrUnexpectedEOF.
+	ErrTruncatedHeader = errors.New("truncated packet header")
+
 	// ErrCommandTooLong is returned when executed command length is bigger
 	// than MaxCommandLen characters.
 	ErrCommandTooLong = errors.New("command too long")
@@ -85,209 +123,1748 @@ var (
 	// ErrCommandEmpty is returned when executed command length equal 0.
 	ErrCommandEmpty = errors.New("command too small")
 
+	// ErrTooManyPackets is returned when multi-packet response has more
+	// packets than allowed by SetMaxResponsePackets.
+	ErrTooManyPackets = errors.New("too many response packets")
+
+	// ErrBanned is returned when the server response matches a ban pattern of
+	// BanPatterns or SetBanPatterns. Retrying is likely to prolong the ban.
+	ErrBanned = errors.New("banned by server")
+
+	// ErrEmptyPassword is returned when the password resolved by
+	// PasswordFromEnv or PasswordFromFile is empty.
+	ErrEmptyPassword = errors.New("password is empty")
+
+	// ErrConnClosed is returned when the command is executed on the closed
+	// connection.
+	ErrConnClosed = errors.New("connection closed")
+
+	// ErrEmptyResponse is returned when the server responds with an empty body
+	// and SetTreatEmptyAsError is enabled.
+	ErrEmptyResponse = errors.New("empty response")
+
+	// ErrDraining is returned when the command is executed on the connection
+	// which is drained by Drain.
+	ErrDraining = errors.New("connection draining")
+
+	// ErrNotAuthenticated is returned when the command is executed on the
+	// connection which is not authorized, for example after failed auth with
+	// SetKeepOpenOnAuthFail or on the zero Conn.
+	ErrNotAuthenticated = errors.New("connection not authenticated")
+
+	// ErrUnexpectedResponse is returned by ExecuteExpect when the response
+	// doesn't contain the expected substring and by ServerLimits when the
+	// response can't be parsed.
+	ErrUnexpectedResponse = errors.New("unexpected response")
+
+	// ErrConnExpired is returned when the connection is older than the limit
+	// set by SetMaxConnLifetime and auto-reconnect is disabled.
+	ErrConnExpired = errors.New("connection expired")
+
+	// ErrCanceled is returned when the command is interrupted by Cancel.
+	ErrCanceled = errors.New("command canceled")
+
+	// ErrCallbackPanic is returned when a packet interceptor panics and
+	// SetRecoverCallbacks is enabled.
+	ErrCallbackPanic = errors.New("callback panicked")
+
+	// ErrNoHealthyBackend is returned by BalancedConn when all backends are
+	// closed.
+	ErrNoHealthyBackend = errors.New("no healthy backend")
+
+	// ErrCommandRejected is returned by ExecuteChecked when the response
+	// matches an error pattern of SetErrorPatterns.
+	ErrCommandRejected = errors.New("command rejected by server")
+
 	// ErrMultiErrorOccurred is returned when close connection failed with
 	// error after auth failed.
 	ErrMultiErrorOccurred = errors.New("an error occurred while handling another error")
 )
 
+// connIDs provides ids of connections which order their locks in Swap.
+var connIDs atomic.Uint64
+
 // Conn is source RCON generic stream-oriented network connection.
+// Execute is safe for concurrent use, commands are serialized.
 type Conn struct {
-	conn     net.Conn
-	settings Settings
+	id            uint64
+	conn          net.Conn
+	network       string
+	address       string
+	password      string
+	settings      Settings
+	mu            sync.Mutex
+	deadlineMu    sync.Mutex
+	readDeadline  time.Time
+	writeDeadline time.Time
+	limiter       *rateLimiter
+	lastID        int32
+	truncated     bool
+	closed        atomic.Bool
+	authenticated atomic.Bool
+	state         atomic.Int32
+	stats         connStats
+	audit         *auditLog
+	dialed        time.Time
+	lastPacket    atomic.Pointer[Packet]
+	cancelMu      sync.Mutex
+	cancelCommand context.CancelCauseFunc
+	idleTimer     atomic.Pointer[time.Timer]
+	latencies     latencyWindow
+	draining      atomic.Bool
+	connected     atomic.Bool
 }
 
 // Dial creates a new authorized Conn tcp dialer connection.
 func Dial(address string, password string, options ...Option) (*Conn, error) {
-	settings := DefaultSettings
+	return DialContext(context.Background(), address, password, options...)
+}
+
+// DialContext works like Dial but uses ctx for connecting and authorization.
+// If ctx is done before the connection is authorized, ctx.Err() is returned.
+func DialContext(ctx context.Context, address string, password string, options ...Option) (*Conn, error) {
+	return dialNetwork(ctx, "tcp", address, password, options)
+}
+
+// DialUnix works like Dial but connects to the Unix domain socket at
+// socketPath, for example of a server which allows local administration only.
+func DialUnix(socketPath string, password string, options ...Option) (*Conn, error) {
+	return dialNetwork(context.Background(), "unix", socketPath, password, options)
+}
+
+// dialNetwork connects to address on network and authorizes the connection.
+func dialNetwork(ctx context.Context, network string, address string, password string, options []Option) (*Conn, error) {
+	client, err := newClient(network, address, password, options)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+
+	client.setState(StateConnecting)
 
-	for _, option := range options {
-		option(&settings)
+	if err := client.dial(ctx); err != nil {
+		err = contextErr(ctx, err)
+		client.setState(StateErrored)
+		client.observeConnect(start, time.Time{}, err)
+
+		return nil, err
 	}
 
-	conn, err := net.DialTimeout("tcp", address, settings.dialTimeout)
+	authStart := time.Now()
+	err = client.authorize(ctx)
+	client.observeConnect(start, authStart, err)
+
+	return client, err
+}
+
+// DialAndExecute connects to the server at address, executes a single command
+// and closes the connection. It sends the command right after the auth request
+// without waiting for the auth response, which saves a round trip for one-shot
+// commands on servers which process requests in order. If auth fails, the error
+// is returned and the server doesn't run the command sent by unauthenticated
+// client. If the server discards commands received before auth completes, the
+// response times out and the timeout error is returned. Sending the command
+// again may run it twice, see SetResendDiscardedCommand.
+func DialAndExecute(address string, password string, command string, options ...Option) (string, error) {
+	client, err := newClient("tcp", address, password, options)
 	if err != nil {
-		// Failed to open TCP connection to the server.
-		return nil, fmt.Errorf("rcon: %w", err)
+		return "", err
+	}
+
+	sent, packetBody, err := client.checkCommandBody(command)
+	if err != nil {
+		return "", err
+	}
+
+	ctx := context.Background()
+	start := time.Now()
+
+	client.setState(StateConnecting)
+
+	if err := client.dial(ctx); err != nil {
+		client.setState(StateErrored)
+		client.observeConnect(start, time.Time{}, err)
+
+		return "", err
+	}
+	defer client.Close()
+
+	var id int32
+
+	authStart := time.Now()
+
+	err = client.authorizeWith(ctx, func() error {
+		if err := client.writeAuth(ctx, client.password); err != nil {
+			return err
+		}
+
+		if id, err = client.sendCommand(ctx, packetBody); err != nil {
+			return err
+		}
+
+		return client.readAuth(ctx)
+	})
+	client.observeConnect(start, authStart, err)
+
+	if err != nil {
+		return "", err
+	}
+
+	response, err := client.readCommand(ctx, id)
+	if isTimeout(err) && client.settings.resendDiscardedCommand {
+		// The command sent before auth completed is discarded by the server.
+		return client.Execute(command)
 	}
 
-	client := Conn{conn: conn, settings: settings}
+	if err == nil {
+		err = client.checkBanned(response.body)
+	}
+
+	if err := client.finishCommand(command, "", start, len(response.body), err); err != nil {
+		return "", err
+	}
+
+	return client.processResponse(command, sent, string(response.body))
+}
+
+// newClient creates Conn to address on network which is not connected yet.
+// TCP addresses are resolved and validated.
+func newClient(network string, address string, password string, options []Option) (*Conn, error) {
+	settings := newSettings(options)
+
+	if network == "tcp" {
+		resolved, err := resolveAddress(address, settings.gameType)
+		if err != nil {
+			return nil, err
+		}
+
+		address = resolved
+	}
+
+	password, err := resolvePassword(&settings, password)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Conn{
+		id:       connIDs.Add(1),
+		network:  network,
+		address:  address,
+		password: password,
+		settings: settings,
+		limiter:  newRateLimiter(settings.rateLimit, settings.rateBurst),
+		audit:    newAuditLog(settings.auditLog),
+	}, nil
+}
+
+// NewConn creates a new authorized Conn over the established connection conn,
+// for example over a custom transport. SetAutoReconnect is ignored because
+// there is no address to redial.
+func NewConn(conn net.Conn, password string, options ...Option) (*Conn, error) {
+	settings := newSettings(options)
+
+	password, err := resolvePassword(&settings, password)
+	if err != nil {
+		return nil, err
+	}
+
+	client := Conn{
+		id:       connIDs.Add(1),
+		conn:     conn,
+		password: password,
+		settings: settings,
+		limiter:  newRateLimiter(settings.rateLimit, settings.rateBurst),
+		audit:    newAuditLog(settings.auditLog),
+	}
+
+	start := time.Now()
+	err = client.authorize(context.Background())
+	client.observeConnect(start, start, err)
+
+	return &client, err
+}
+
+// authorize authenticates c and closes it if authentication failed.
+func (c *Conn) authorize(ctx context.Context) error {
+	return c.authorizeWith(ctx, func() error {
+		return c.auth(ctx, c.password)
+	})
+}
+
+// authorizeWith works like authorize but authenticates c with auth.
+func (c *Conn) authorizeWith(ctx context.Context, auth func() error) error {
+	c.setState(StateConnecting)
+
+	stop := c.watchContext(ctx)
+	err := auth()
+
+	stop()
+
+	if err != nil {
+		c.setState(StateErrored)
+
+		if c.settings.keepOpenOnAuthFail {
+			return fmt.Errorf("rcon: %w", contextErr(ctx, err))
+		}
 
-	if err := client.auth(password); err != nil {
 		// Failed to auth conn with the server.
-		if err2 := client.Close(); err2 != nil {
-			return &client, fmt.Errorf("%w: %s. Previous error: %s", ErrMultiErrorOccurred, err2.Error(), err.Error())
+		if err2 := c.Close(); err2 != nil {
+			return fmt.Errorf("%w: %s. Previous error: %s", ErrMultiErrorOccurred, err2.Error(), err.Error())
 		}
 
-		return &client, fmt.Errorf("rcon: %w", err)
+		return fmt.Errorf("rcon: %w", contextErr(ctx, err))
 	}
 
-	return &client, nil
+	c.stats.connected = time.Now()
+	c.dialed = c.stats.connected
+	c.markUsed()
+	c.authenticated.Store(true)
+	c.setState(StateAuthenticated)
+	c.watchIdle()
+
+	return nil
+}
+
+// DialResult is the result of DialAsync.
+type DialResult struct {
+	Conn *Conn
+	Err  error
+}
+
+// DialAsync works like Dial but connects in a new goroutine. The returned
+// channel receives a single result and is closed then. The channel is
+// buffered, so the goroutine exits even if nobody receives the result.
+func DialAsync(address string, password string, options ...Option) <-chan DialResult {
+	return DialAsyncContext(context.Background(), address, password, options...)
+}
+
+// DialAsyncContext works like DialAsync but uses ctx as DialContext does.
+func DialAsyncContext(ctx context.Context, address string, password string, options ...Option) <-chan DialResult {
+	result := make(chan DialResult, 1)
+
+	go func() {
+		defer close(result)
+
+		conn, err := DialContext(ctx, address, password, options...)
+		result <- DialResult{Conn: conn, Err: err}
+	}()
+
+	return result
 }
 
 // Execute sends command type and it string to execute to the remote server,
-// creating a packet with a SERVERDATA_EXECCOMMAND_ID for the server to mirror,
+// creating a packet with a generated request ID for the server to mirror,
 // and compiling its payload bytes in the appropriate order. The response body
-// is decompiled from bytes into a string for return.
+// is decompiled from bytes into a string for return. Many commands succeed
+// without output, their empty response is returned as "" with nil error
+// unless SetTreatEmptyAsError is enabled.
 func (c *Conn) Execute(command string) (string, error) {
-	if command == "" {
-		return "", ErrCommandEmpty
-	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	if len(command) > MaxCommandLen {
-		return "", ErrCommandTooLong
-	}
+	return c.execute(context.Background(), command, "")
+}
 
-	if err := c.write(SERVERDATA_EXECCOMMAND, SERVERDATA_EXECCOMMAND_ID, command); err != nil {
-		return "", err
+// ExecuteTagged works like Execute and passes tag to the observer, which
+// allows to group commands, for example by category in metrics.
+func (c *Conn) ExecuteTagged(command string, tag string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.execute(context.Background(), command, tag)
+}
+
+// ExecuteContext works like Execute but uses ctx for waiting the rate limit
+// and for the network operations. If ctx is done before the response is read,
+// ctx.Err() is returned. If ctx has a deadline earlier than the connection
+// deadline, the ctx deadline is used. Note that a command interrupted in the
+// middle of the response leaves a rest of the response unread.
+func (c *Conn) ExecuteContext(ctx context.Context, command string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.execute(ctx, command, "")
+}
+
+// TryExecute works like Execute but does not wait for another in-flight
+// command. If the connection is busy, it returns immediately with ok set to
+// false and nothing is sent to the server. Otherwise ok is true and the
+// response and error are the same as from Execute.
+func (c *Conn) TryExecute(command string) (response string, ok bool, err error) {
+	if !c.mu.TryLock() {
+		return "", false, nil
 	}
+	defer c.mu.Unlock()
 
-	response, err := c.read()
+	response, err = c.execute(context.Background(), command, "")
+
+	return response, true, err
+}
+
+// ExecuteExpect works like Execute and checks that the response contains
+// expectSubstring, for example the acknowledgment of a kick. Otherwise it
+// returns the response and an error wrapping ErrUnexpectedResponse.
+func (c *Conn) ExecuteExpect(command string, expectSubstring string) (string, error) {
+	response, err := c.Execute(command)
 	if err != nil {
-		return response.Body(), err
+		return response, err
 	}
 
-	if response.ID != SERVERDATA_EXECCOMMAND_ID {
-		return response.Body(), ErrInvalidPacketID
+	if !strings.Contains(response, expectSubstring) {
+		return response, fmt.Errorf("rcon: %w: got %q, want to contain %q", ErrUnexpectedResponse, response, expectSubstring)
 	}
 
-	return response.Body(), nil
+	return response, nil
 }
 
-// LocalAddr returns the local network address.
-func (c *Conn) LocalAddr() net.Addr {
-	return c.conn.LocalAddr()
+// ExecuteBytes works like Execute but returns the raw response body bytes.
+// Response options such as SetTrimResponse are not applied.
+func (c *Conn) ExecuteBytes(command string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.executeBytes(context.Background(), command, "")
 }
 
-// RemoteAddr returns the remote network address.
-func (c *Conn) RemoteAddr() net.Addr {
-	return c.conn.RemoteAddr()
+// ExecuteRaw is an advanced variant of ExecuteBytes for servers whose commands
+// are binary, for example with non-UTF-8 bytes or embedded nulls. The body is
+// sent exactly as given and its length in bytes is limited by MaxCommandLen.
+// Command options such as SetAppendNewline, SetAliases, SetCommandPipeline and
+// SetTranscoder are not applied.
+func (c *Conn) ExecuteRaw(body []byte) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	start := time.Now()
+
+	c.lastPacket.Store(nil)
+	c.truncated = false
+
+	// The body bypasses commandBody, so aliases, the command pipeline, the
+	// newline and the transcoder are not applied.
+	if err := c.checkRawCommand(body); err != nil {
+		c.observeCommand(string(body), "", start, 0, err)
+
+		return nil, err
+	}
+
+	return c.executeChecked(context.Background(), string(body), string(body), "", start)
 }
 
-// Close closes the connection.
-func (c *Conn) Close() error {
-	return c.conn.Close()
+// ExecuteFunc works like Execute but calls onPacket with the raw body of each
+// response packet as soon as it is read, without reassembling the response.
+// With multi-packet responses, it is called for each packet until the sentinel
+// is mirrored. Returning an error from onPacket aborts reading and the error is
+// returned wrapped. The rest of the response is left unread then.
+func (c *Conn) ExecuteFunc(command string, onPacket func(body string) error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.executeFunc(context.Background(), command, func(packet *Packet) error {
+		body := string(packet.body)
+
+		if c.settings.transcoder != nil {
+			decoded, err := c.settings.transcoder.Decode(body)
+			if err != nil {
+				return fmt.Errorf("rcon: decode response: %w", err)
+			}
+
+			body = decoded
+		}
+
+		if err := onPacket(body); err != nil {
+			return fmt.Errorf("rcon: packet callback: %w", err)
+		}
+
+		return nil
+	})
 }
 
-// auth sends SERVERDATA_AUTH request to the remote server and
-// authenticates client for the next requests.
-func (c *Conn) auth(password string) error {
-	if err := c.write(SERVERDATA_AUTH, SERVERDATA_AUTH_ID, password); err != nil {
+// ExecuteStream works like ExecuteBytes but returns the reader of the response
+// body, which must be closed by the caller. With SetSpoolThreshold responses
+// larger than the threshold are spooled to a temp file during reassembly
+// instead of memory, the file is removed when the reader is closed.
+func (c *Conn) ExecuteStream(command string) (io.ReadCloser, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	body := spool{threshold: c.settings.spoolThreshold}
+
+	err := c.executeFunc(context.Background(), command, func(packet *Packet) error {
+		_, err := body.Write(packet.body)
+
 		return err
-	}
+	})
+	if err != nil {
+		body.discard()
 
-	if c.settings.deadline != 0 {
-		if err := c.conn.SetReadDeadline(time.Now().Add(c.settings.deadline)); err != nil {
-			return fmt.Errorf("rcon: %w", err)
-		}
+		return nil, err
 	}
 
-	response, err := c.readHeader()
+	return body.reader()
+}
+
+// executeFunc sends command to the remote server and calls callback with each
+// response packet. c.mu must be held by the caller.
+func (c *Conn) executeFunc(ctx context.Context, command string, callback func(packet *Packet) error) error {
+	start := time.Now()
+
+	c.lastPacket.Store(nil)
+	c.truncated = false
+
+	_, packetBody, err := c.checkCommand(command)
 	if err != nil {
+		c.observeCommand(command, "", start, 0, err)
+
 		return err
 	}
 
-	size := response.Size - PacketHeaderSize
-	if size < 0 {
-		return ErrAuthNotRCON
+	ctx, done := c.cancelable(ctx)
+	defer done()
+
+	stop := c.watchContext(ctx)
+	defer stop()
+
+	c.setState(StateExecuting)
+
+	size := 0
+
+	err = c.readFunc(ctx, packetBody, func(packet *Packet) error {
+		size += len(packet.body)
+
+		return callback(packet)
+	})
+	if err != nil {
+		err = contextErr(ctx, err)
 	}
 
-	// When the server receives an auth request, it will respond with an empty
-	// SERVERDATA_RESPONSE_VALUE, followed immediately by a SERVERDATA_AUTH_RESPONSE
-	// indicating whether authentication succeeded or failed.
-	// Some servers doesn't send an empty SERVERDATA_RESPONSE_VALUE packet, so we
-	// do this case optional.
-	if response.Type == SERVERDATA_RESPONSE_VALUE {
-		// Discard empty SERVERDATA_RESPONSE_VALUE from authentication response.
-		_, _ = c.conn.Read(make([]byte, size))
+	return c.finishCommand(command, "", start, size, err)
+}
 
-		if response, err = c.readHeader(); err != nil {
-			return err
-		}
+// readFunc sends packetBody and calls callback with each response packet.
+func (c *Conn) readFunc(ctx context.Context, packetBody string, callback func(packet *Packet) error) error {
+	if err := c.checkLifetime(ctx); err != nil {
+		return err
 	}
 
-	// We must to read response body.
-	buffer := make([]byte, size)
-	if _, err := c.conn.Read(buffer); err != nil {
-		return fmt.Errorf("rcon: %w", err)
+	if err := c.limiter.wait(ctx); err != nil {
+		return err
 	}
 
-	if response.Type != SERVERDATA_AUTH_RESPONSE {
-		return ErrInvalidAuthResponse
+	id, err := c.sendCommand(ctx, packetBody)
+	if err != nil {
+		return err
 	}
 
-	if response.ID == -1 {
-		return ErrAuthFailed
+	if c.settings.multiPacket {
+		return c.readFragments(ctx, id, c.terminatedFunc(callback))
+	}
+
+	response, err := c.read(ctx, id)
+	if err != nil {
+		return err
 	}
 
-	if response.ID != SERVERDATA_AUTH_ID {
+	if response.ID != id {
 		return ErrInvalidPacketID
 	}
 
+	c.truncated = isTruncated(response)
+
+	if err := callback(response); err != nil {
+		return err
+	}
+
+	if c.settings.expectTrailingStatus {
+		return c.readTrailingStatus(ctx)
+	}
+
 	return nil
 }
 
-// write creates packet and writes it to established tcp conn.
-func (c *Conn) write(packetType int32, packetID int32, command string) error {
-	if c.settings.deadline != 0 {
-		if err := c.conn.SetWriteDeadline(time.Now().Add(c.settings.deadline)); err != nil {
-			return fmt.Errorf("rcon: %w", err)
-		}
-	}
+// LastResponseTruncated reports whether the last Execute response body filled
+// the maximum packet size, which suggests that the server split the response
+// and more data followed. It is a heuristic, not a guarantee: a response that
+// exactly fits the maximum packet size is reported as truncated too. It is
+// false if the last command failed.
+func (c *Conn) LastResponseTruncated() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	packet := NewPacket(packetType, packetID, command)
-	_, err := packet.WriteTo(c.conn)
+	return c.truncated
+}
 
-	return err
+// Raw returns the underlying network connection. Use it at your own risk:
+// reading from or writing to it bypasses the protocol state machine and breaks
+// the following commands. It is intended for setting socket options and for
+// instrumentation only. The returned connection is replaced after reconnect.
+func (c *Conn) Raw() net.Conn {
+	c.deadlineMu.Lock()
+	defer c.deadlineMu.Unlock()
+
+	return c.conn
 }
 
-// read reads structured binary data from c.conn into packet.
-func (c *Conn) read() (*Packet, error) {
-	if c.settings.deadline != 0 {
-		if err := c.conn.SetReadDeadline(time.Now().Add(c.settings.deadline)); err != nil {
-			return nil, fmt.Errorf("rcon: %w", err)
-		}
+// LocalAddr returns the local network address.
+func (c *Conn) LocalAddr() net.Addr {
+	return c.Raw().LocalAddr()
+}
+
+// RemoteAddr returns the remote network address.
+func (c *Conn) RemoteAddr() net.Addr {
+	return c.Raw().RemoteAddr()
+}
+
+// SetDeadline sets the read and write deadlines of the connection. It works
+// like net.Conn SetDeadline: a non-zero deadline applies to all future and
+// pending operations. While it is set, it replaces the per-operation timeouts
+// of the SetDeadline and SetReadIdleTimeout options, which allows to tighten
+// or loosen them for the following commands. A zero value clears it and the
+// per-operation timeouts apply again. ExecuteTimeout replaces it for its
+// command and an earlier deadline of the context in ExecuteContext still wins.
+func (c *Conn) SetDeadline(t time.Time) error {
+	c.deadlineMu.Lock()
+	defer c.deadlineMu.Unlock()
+
+	c.readDeadline, c.writeDeadline = t, t
+
+	if err := c.conn.SetDeadline(t); err != nil {
+		return fmt.Errorf("rcon: %w", err)
 	}
 
-	packet := &Packet{}
-	if _, err := packet.ReadFrom(c.conn); err != nil {
-		return packet, err
+	return nil
+}
+
+// SetReadDeadline sets the read deadline of the connection. See SetDeadline
+// for details.
+func (c *Conn) SetReadDeadline(t time.Time) error {
+	c.deadlineMu.Lock()
+	defer c.deadlineMu.Unlock()
+
+	c.readDeadline = t
+
+	if err := c.conn.SetReadDeadline(t); err != nil {
+		return fmt.Errorf("rcon: %w", err)
 	}
 
-	// Workaround for Rust server.
-	// Rust rcon server responses packet with a type of 4 and the next packet
-	// is valid. It is undocumented, so skip packet and read next.
-	if packet.Type == 4 {
-		if _, err := packet.ReadFrom(c.conn); err != nil {
-			return packet, err
-		}
+	return nil
+}
 
-		// One more workaround for Rust server.
-		// When sent command "Say" there is no response data from server with
-		// packet.ID = SERVERDATA_EXECCOMMAND_ID, only previous console message
-		// that command was received with packet.ID = -1, therefore, forcibly
-		// set packet.ID to SERVERDATA_EXECCOMMAND_ID.
-		if packet.ID == -1 {
-			packet.ID = SERVERDATA_EXECCOMMAND_ID
-		}
+// SetWriteDeadline sets the write deadline of the connection. See SetDeadline
+// for details.
+func (c *Conn) SetWriteDeadline(t time.Time) error {
+	c.deadlineMu.Lock()
+	defer c.deadlineMu.Unlock()
+
+	c.writeDeadline = t
+
+	if err := c.conn.SetWriteDeadline(t); err != nil {
+		return fmt.Errorf("rcon: %w", err)
 	}
 
-	return packet, nil
+	return nil
 }
 
-// readHeader reads structured binary data without body from c.conn into packet.
-func (c *Conn) readHeader() (Packet, error) {
-	var packet Packet
-	if err := binary.Read(c.conn, binary.LittleEndian, &packet.Size); err != nil {
-		return packet, fmt.Errorf("rcon: read packet size: %w", err)
+// Close closes the connection. Close is idempotent, calls after the first one
+// return nil. It may be called concurrently with Execute to interrupt it.
+// Commands executed after Close fail with ErrConnClosed.
+func (c *Conn) Close() error {
+	if c.closed.Swap(true) {
+		return nil
+	}
+
+	if timer := c.idleTimer.Load(); timer != nil {
+		timer.Stop()
 	}
 
-	if err := binary.Read(c.conn, binary.LittleEndian, &packet.ID); err != nil {
-		return packet, fmt.Errorf("rcon: read packet id: %w", err)
+	err := c.Raw().Close()
+	c.audit.close()
+	c.setState(StateClosed)
+	c.observeClose(err)
+
+	return err
+}
+
+// LastPacket returns a copy of the last packet read by the latest command, for
+// example to debug responses of a misbehaving server. With multi-packet
+// responses it is the last read packet, usually the mirrored sentinel. It
+// returns nil if the latest command has read no packet.
+func (c *Conn) LastPacket() *Packet {
+	return c.lastPacket.Load().clone()
+}
+
+// IsClosed reports whether Close has been called.
+func (c *Conn) IsClosed() bool {
+	return c.closed.Load()
+}
+
+// execute sends command to the remote server and returns the response body
+// processed according to the settings. c.mu must be held by the caller.
+func (c *Conn) execute(ctx context.Context, command string, tag string) (string, error) {
+	sent, body, err := c.executeCommand(ctx, command, tag)
+	if err != nil {
+		return string(body), err
 	}
 
-	if err := binary.Read(c.conn, binary.LittleEndian, &packet.Type); err != nil {
-		return packet, fmt.Errorf("rcon: read packet type: %w", err)
+	return c.processResponse(command, sent, string(body))
+}
+
+// executeBytes sends command to the remote server, returns the raw response
+// body and passes the result to the observer. c.mu must be held by the caller.
+func (c *Conn) executeBytes(ctx context.Context, command string, tag string) ([]byte, error) {
+	_, body, err := c.executeCommand(ctx, command, tag)
+
+	return body, err
+}
+
+// executeCommand works like executeBytes and also returns command as sent
+// after aliases and the command pipeline. c.mu must be held by the caller.
+func (c *Conn) executeCommand(ctx context.Context, command string, tag string) (string, []byte, error) {
+	start := time.Now()
+
+	c.lastPacket.Store(nil)
+	c.truncated = false
+
+	sent, packetBody, err := c.checkCommand(command)
+	if err != nil {
+		c.observeCommand(command, tag, start, 0, err)
+
+		return sent, nil, err
 	}
 
-	return packet, nil
+	body, err := c.executeChecked(ctx, command, packetBody, tag, start)
+
+	return sent, body, err
+}
+
+// executeChecked sends packetBody of the checked command started at start and
+// returns the raw response body. c.mu must be held by the caller.
+func (c *Conn) executeChecked(ctx context.Context, command string, packetBody string, tag string, start time.Time) ([]byte, error) {
+	ctx, done := c.cancelable(ctx)
+	defer done()
+
+	c.setState(StateExecuting)
+
+	body, err := c.exchange(ctx, command, packetBody)
+
+	return body, c.finishCommand(command, tag, start, len(body), err)
+}
+
+// finishCommand records the result of command started at start to the state,
+// the stats and the observer. Every command path calls it once per command.
+// It returns ErrEmptyResponse for the successful empty response if
+// SetTreatEmptyAsError is enabled, otherwise err.
+func (c *Conn) finishCommand(command string, tag string, start time.Time, size int, err error) error {
+	if err == nil && size == 0 && c.settings.treatEmptyAsError {
+		err = ErrEmptyResponse
+	}
+
+	if err != nil {
+		c.setState(StateErrored)
+	} else {
+		c.setState(StateAuthenticated)
+	}
+
+	c.stats.recordCommand(time.Since(start), err)
+	c.markUsed()
+
+	if err == nil && c.settings.adaptiveTimeout {
+		c.latencies.add(time.Since(start))
+	}
+	c.observeCommand(command, tag, start, size, err)
+
+	return err
+}
+
+// exchange sends packetBody of checked command to the remote server and
+// returns the raw response body.
+func (c *Conn) exchange(ctx context.Context, command string, packetBody string) ([]byte, error) {
+	if err := c.limiter.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	stop := c.watchContext(ctx)
+	defer stop()
+
+	if err := c.checkLifetime(ctx); err != nil {
+		return nil, contextErr(ctx, err)
+	}
+
+	response, err := c.roundTrip(ctx, packetBody)
+	if banErr := c.checkDisconnectBanned(err); banErr != nil {
+		return response.body, banErr
+	}
+
+	if err != nil && c.settings.reconnectAttempts > 0 && c.address != "" && isConnError(err) {
+		response, err = c.reconnectAndRetry(ctx, packetBody, err)
+	}
+
+	if err != nil {
+		if c.IsClosed() {
+			// Connection was closed while the command was in flight.
+			return response.body, ErrConnClosed
+		}
+
+		return response.body, contextErr(ctx, err)
+	}
+
+	if err := c.checkBanned(response.body); err != nil {
+		return response.body, err
+	}
+
+	return c.validateResponse(ctx, command, packetBody, response.body)
+}
+
+// reconnectAndRetry reconnects to the server after the connection failed with
+// err and sends packetBody again. Backoffs and reconnects share the ctx
+// deadline, so it returns ctx.Err() as soon as ctx is done.
+func (c *Conn) reconnectAndRetry(ctx context.Context, packetBody string, err error) (*Packet, error) {
+	response := &Packet{}
+
+	for attempt := 0; attempt < c.settings.reconnectAttempts && isConnError(err) && !c.IsClosed(); attempt++ {
+		if err = sleepContext(ctx, c.reconnectDelay(attempt)); err != nil {
+			return response, err
+		}
+
+		if err = c.reconnect(ctx); err != nil {
+			continue
+		}
+
+		response, err = c.roundTrip(ctx, packetBody)
+	}
+
+	return response, err
+}
+
+// reconnect closes the current connection and establishes a new authorized one.
+func (c *Conn) reconnect(ctx context.Context) error {
+	_ = c.conn.Close()
+
+	if err := c.dial(ctx); err != nil {
+		return err
+	}
+
+	if err := c.auth(ctx, c.password); err != nil {
+		return fmt.Errorf("rcon: %w", err)
+	}
+
+	c.dialed = time.Now()
+
+	return nil
+}
+
+// checkLifetime redials the connection which is older than SetMaxConnLifetime
+// if auto-reconnect is enabled, otherwise it returns ErrConnExpired. c.mu must
+// be held by the caller.
+func (c *Conn) checkLifetime(ctx context.Context) error {
+	if c.settings.maxConnLifetime <= 0 || time.Since(c.dialed) < c.settings.maxConnLifetime {
+		return nil
+	}
+
+	if c.settings.reconnectAttempts == 0 || c.address == "" {
+		return ErrConnExpired
+	}
+
+	if err := c.reconnect(ctx); err != nil {
+		return fmt.Errorf("rcon: %w: redial: %w", ErrConnExpired, err)
+	}
+
+	return nil
+}
+
+// dial opens a new tcp connection to the server and replaces c.conn with it.
+func (c *Conn) dial(ctx context.Context) error {
+	dialer := net.Dialer{Timeout: c.settings.dialTimeout}
+
+	conn, err := dialer.DialContext(ctx, c.network, c.address)
+	if err != nil {
+		// Failed to open TCP connection to the server.
+		return fmt.Errorf("rcon: %w", err)
+	}
+
+	if c.settings.keepAlive {
+		err = setTCPKeepAlive(conn, c.settings.keepAliveIdle, c.settings.keepAliveInterval, c.settings.keepAliveCount)
+		if err != nil {
+			_ = conn.Close()
+
+			return err
+		}
+	}
+
+	if err := setSocketBuffers(conn, c.settings.readBuffer, c.settings.writeBuffer); err != nil {
+		_ = conn.Close()
+
+		return err
+	}
+
+	if c.tlsEnabled() {
+		tlsConn, err := c.tlsClient(ctx, conn)
+		if err != nil {
+			_ = conn.Close()
+
+			return err
+		}
+
+		conn = tlsConn
+	}
+
+	c.deadlineMu.Lock()
+	defer c.deadlineMu.Unlock()
+
+	c.conn = conn
+
+	return nil
+}
+
+// checkCommand returns command as sent after aliases and the command pipeline
+// and its packet body or an error if command can't be executed.
+func (c *Conn) checkCommand(command string) (string, string, error) {
+	if err := c.checkConn(); err != nil {
+		return "", "", err
+	}
+
+	return c.checkCommandBody(command)
+}
+
+// checkRawCommand returns error if body can't be sent by ExecuteRaw.
+func (c *Conn) checkRawCommand(body []byte) error {
+	if err := c.checkConn(); err != nil {
+		return err
+	}
+
+	if len(body) == 0 {
+		return ErrCommandEmpty
+	}
+
+	if len(body) > MaxCommandLen {
+		return ErrCommandTooLong
+	}
+
+	return nil
+}
+
+// checkConn returns error if the connection can't execute commands.
+func (c *Conn) checkConn() error {
+	// Drain closes the connection, but commands called in the meantime are
+	// reported as refused by draining.
+	if c.draining.Load() {
+		return ErrDraining
+	}
+
+	if c.IsClosed() {
+		return ErrConnClosed
+	}
+
+	if !c.authenticated.Load() {
+		return ErrNotAuthenticated
+	}
+
+	return nil
+}
+
+// checkCommandBody returns command as sent after aliases and the command
+// pipeline and its packet body or error if command can't be sent as a packet
+// body. The command is transformed once, callers pass the results down.
+func (c *Conn) checkCommandBody(command string) (string, string, error) {
+	if command == "" {
+		return "", "", ErrCommandEmpty
+	}
+
+	sent, err := c.transformCommand(command)
+	if err != nil {
+		return sent, "", err
+	}
+
+	body, err := c.encodeCommand(sent)
+	if err != nil {
+		return sent, body, err
+	}
+
+	if len(body) > MaxCommandLen {
+		return sent, body, ErrCommandTooLong
+	}
+
+	return sent, body, nil
+}
+
+// commandBody returns the packet body for command according to the settings.
+func (c *Conn) commandBody(command string) (string, error) {
+	command, err := c.transformCommand(command)
+	if err != nil {
+		return command, err
+	}
+
+	return c.encodeCommand(command)
+}
+
+// encodeCommand returns the packet body for the transformed command with the
+// newline and the transcoder applied.
+func (c *Conn) encodeCommand(command string) (string, error) {
+	if c.settings.appendNewline {
+		command += "\n"
+	}
+
+	if c.settings.transcoder == nil {
+		return command, nil
+	}
+
+	body, err := c.settings.transcoder.Encode(command)
+	if err != nil {
+		return body, fmt.Errorf("rcon: encode command: %w", err)
+	}
+
+	return body, nil
+}
+
+// transformCommand expands aliases of command and passes it through the
+// command pipeline.
+func (c *Conn) transformCommand(command string) (string, error) {
+	command, err := c.expandAlias(command)
+	if err != nil {
+		return command, err
+	}
+
+	for _, fn := range c.settings.commandPipeline {
+		transformed, err := fn(command)
+		if err != nil {
+			return transformed, fmt.Errorf("rcon: command pipeline: %w", err)
+		}
+
+		command = transformed
+	}
+
+	return command, nil
+}
+
+// processResponse applies response settings to the response body of command
+// which was sent as sent after aliases and the command pipeline.
+func (c *Conn) processResponse(command string, sent string, response string) (string, error) {
+	if c.settings.transcoder != nil {
+		decoded, err := c.settings.transcoder.Decode(response)
+		if err != nil {
+			return response, fmt.Errorf("rcon: decode response: %w", err)
+		}
+
+		response = decoded
+	}
+
+	if c.settings.stripColorCodes {
+		response = rconminecraft.StripColors(response)
+	}
+
+	if c.settings.stripCommandEcho {
+		response = stripCommandEcho(command, sent, response)
+	}
+
+	if c.settings.trimResponse {
+		response = strings.TrimRight(response, trimCutset)
+	}
+
+	for _, fn := range c.settings.responsePipeline {
+		transformed, err := fn(response)
+		if err != nil {
+			return transformed, fmt.Errorf("rcon: response pipeline: %w", err)
+		}
+
+		response = transformed
+	}
+
+	return response, nil
+}
+
+// roundTrip writes command packets with packetBody and reads the response
+// packet.
+func (c *Conn) roundTrip(ctx context.Context, packetBody string) (*Packet, error) {
+	id, err := c.sendCommand(ctx, packetBody)
+	if err != nil {
+		return &Packet{}, err
+	}
+
+	return c.readCommand(ctx, id)
+}
+
+// readCommand reads the response to the command sent with request id.
+func (c *Conn) readCommand(ctx context.Context, id int32) (*Packet, error) {
+	response, err := c.readCommandResponse(ctx, id)
+	if err != nil {
+		return response, err
+	}
+
+	if response.ID != id {
+		return response, ErrInvalidPacketID
+	}
+
+	return response, nil
+}
+
+// sendCommand writes command packets with packetBody and a new request id and
+// returns the id. packetBody is sent as is, see commandBody.
+func (c *Conn) sendCommand(ctx context.Context, packetBody string) (int32, error) {
+	id, err := c.nextRequestID()
+	if err != nil {
+		return id, err
+	}
+
+	if err := c.write(ctx, SERVERDATA_EXECCOMMAND, id, packetBody); err != nil {
+		return id, err
+	}
+
+	if c.settings.multiPacket && c.settings.responseTerminator == "" {
+		// The server responses to SERVERDATA_RESPONSE_VALUE after it has sent
+		// all packets of the command response, so the mirrored sentinel packet
+		// marks the end of the response.
+		if err := c.write(ctx, SERVERDATA_RESPONSE_VALUE, c.settings.sentinelID, ""); err != nil {
+			return id, err
+		}
+	}
+
+	return id, nil
+}
+
+// auth sends SERVERDATA_AUTH request to the remote server and
+// authenticates client for the next requests.
+func (c *Conn) auth(ctx context.Context, password string) error {
+	if err := c.writeAuth(ctx, password); err != nil {
+		return err
+	}
+
+	return c.readAuth(ctx)
+}
+
+// writeAuth sends SERVERDATA_AUTH request to the remote server.
+func (c *Conn) writeAuth(ctx context.Context, password string) error {
+	return c.write(ctx, SERVERDATA_AUTH, SERVERDATA_AUTH_ID, password)
+}
+
+// readAuth reads the response to SERVERDATA_AUTH request.
+func (c *Conn) readAuth(ctx context.Context) error {
+	if err := c.setReadDeadline(ctx, c.settings.deadline); err != nil {
+		return err
+	}
+
+	response, err := c.readAuthPacket()
+	if err != nil {
+		if banErr := c.checkDisconnectBanned(err); banErr != nil {
+			return banErr
+		}
+
+		return err
+	}
+
+	if err := c.checkAuthBanned(response.body); err != nil {
+		return err
+	}
+
+	// When the server receives an auth request, it will respond with an empty
+	// SERVERDATA_RESPONSE_VALUE, followed immediately by a SERVERDATA_AUTH_RESPONSE
+	// indicating whether authentication succeeded or failed.
+	// Some servers doesn't send an empty SERVERDATA_RESPONSE_VALUE packet, so we
+	// do this case optional.
+	if c.settings.authOrder == EmptyThenAuth && response.Type == SERVERDATA_RESPONSE_VALUE {
+		// Discard empty SERVERDATA_RESPONSE_VALUE from authentication response.
+		if response, err = c.readAuthPacket(); err != nil {
+			if banErr := c.checkDisconnectBanned(err); banErr != nil {
+				return banErr
+			}
+
+			return err
+		}
+
+		if err := c.checkAuthBanned(response.body); err != nil {
+			return err
+		}
+	}
+
+	if response.Type != SERVERDATA_AUTH_RESPONSE {
+		return ErrInvalidAuthResponse
+	}
+
+	if response.ID == -1 {
+		return ErrAuthFailed
+	}
+
+	if response.ID != SERVERDATA_AUTH_ID && !c.isConanExilesID(response.ID) {
+		return ErrInvalidPacketID
+	}
+
+	if c.settings.authOrder == AuthThenEmpty {
+		// Discard empty SERVERDATA_RESPONSE_VALUE which follows successful
+		// authentication response, otherwise it is read as a command response.
+		if response, err = c.readAuthPacket(); err != nil {
+			return err
+		}
+
+		if response.Type != SERVERDATA_RESPONSE_VALUE {
+			return ErrInvalidAuthResponse
+		}
+	}
+
+	return nil
+}
+
+// write creates packet and writes it to established tcp conn.
+func (c *Conn) write(ctx context.Context, packetType int32, packetID int32, command string) error {
+	if err := c.setWriteDeadline(ctx, commandTimeout(ctx, c.settings.deadline)); err != nil {
+		return err
+	}
+
+	packet := NewPacket(packetType, packetID, command)
+
+	if packetType == SERVERDATA_EXECCOMMAND && packet.Size < c.settings.minPacketSize {
+		// Servers read the command up to the first null, so the nulls are
+		// ignored.
+		packet = NewPacket(packetType, packetID, command+strings.Repeat("\x00", int(c.settings.minPacketSize-packet.Size)))
+	}
+
+	if c.settings.writeInterceptor != nil {
+		intercepted, err := c.callInterceptor(c.settings.writeInterceptor, *packet)
+		if err != nil {
+			return fmt.Errorf("rcon: write interceptor: %w", err)
+		}
+
+		packet = &intercepted
+	}
+
+	return c.writePacket(packet)
+}
+
+// readCommandResponse reads the response to SERVERDATA_EXECCOMMAND request.
+func (c *Conn) readCommandResponse(ctx context.Context, id int32) (*Packet, error) {
+	if c.settings.multiPacket {
+		return c.readMultiPacket(ctx, id)
+	}
+
+	response, err := c.read(ctx, id)
+	if err != nil {
+		return response, err
+	}
+
+	c.truncated = isTruncated(response)
+
+	if c.settings.expectTrailingStatus {
+		if err := c.readTrailingStatus(ctx); err != nil {
+			return response, err
+		}
+	}
+
+	return response, nil
+}
+
+// readTrailingStatus reads and discards the status packet which the server
+// sends after the response. It is not checked, because servers send it with
+// various types and ids.
+func (c *Conn) readTrailingStatus(ctx context.Context) error {
+	if err := c.setReadDeadline(ctx, commandTimeout(ctx, c.CurrentTimeout())); err != nil {
+		return err
+	}
+
+	_, err := c.readPacket()
+
+	return err
+}
+
+// sentinelTrailer is the body of the packet which SRCDS sends after the
+// mirrored sentinel without the padding.
+var sentinelTrailer = []byte{0x00, 0x01}
+
+// errResponseEnd is returned by fragment functions when the response
+// terminator is found, so readFragments stops without an error.
+var errResponseEnd = errors.New("rcon: response terminator found")
+
+// readMultiPacket reads response packets until the server mirrors the sentinel
+// packet or the response terminator appears and reassembles them into a single
+// packet without the terminator.
+func (c *Conn) readMultiPacket(ctx context.Context, id int32) (*Packet, error) {
+	var body bytes.Buffer
+
+	body.Grow(c.settings.readBufferHint)
+
+	marker := []byte(c.settings.responseTerminator)
+
+	err := c.readFragments(ctx, id, func(packet *Packet) error {
+		// The terminator may be split between packets, so the search starts
+		// before the new packet body.
+		from := max(body.Len()-len(marker)+1, 0)
+
+		body.Write(packet.body)
+
+		if len(marker) > 0 {
+			if i := bytes.Index(body.Bytes()[from:], marker); i >= 0 {
+				body.Truncate(from + i)
+
+				return errResponseEnd
+			}
+		}
+
+		return nil
+	})
+
+	return newPacket(SERVERDATA_RESPONSE_VALUE, id, body.Bytes()), err
+}
+
+// terminatedFunc wraps fn to cut each packet body at the response terminator
+// and to stop reading after it. The terminator is only found within a single
+// packet.
+func (c *Conn) terminatedFunc(fn func(packet *Packet) error) func(packet *Packet) error {
+	marker := []byte(c.settings.responseTerminator)
+	if len(marker) == 0 {
+		return fn
+	}
+
+	return func(packet *Packet) error {
+		i := bytes.Index(packet.body, marker)
+		if i < 0 {
+			return fn(packet)
+		}
+
+		packet.body = packet.body[:i]
+
+		if err := fn(packet); err != nil {
+			return err
+		}
+
+		return errResponseEnd
+	}
+}
+
+// readFragments reads packets of multi-packet response and passes each of them
+// to fn until the server mirrors the sentinel packet. If the read idle timeout
+// is set and no packet arrives within it, the response is considered complete.
+// An error returned by fn stops reading, errResponseEnd ends the response.
+func (c *Conn) readFragments(ctx context.Context, id int32, fn func(packet *Packet) error) error {
+	response, err := c.read(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	for packets := 1; response.ID != c.settings.sentinelID; packets++ {
+		if response.ID != id {
+			return ErrInvalidPacketID
+		}
+
+		if c.tooManyPackets(packets) {
+			return ErrTooManyPackets
+		}
+
+		c.truncated = isTruncated(response)
+
+		if err := fn(response); err != nil {
+			if errors.Is(err, errResponseEnd) {
+				return nil
+			}
+
+			return err
+		}
+
+		if response, err = c.readNext(ctx, id); err != nil {
+			// The server doesn't mirror the sentinel and has sent all packets
+			// before the read idle timeout, so the response is complete.
+			// Without the idle timeout the deadline is exceeded by a stalled
+			// server, so the partial response is an error.
+			if c.isIdleTimeout(ctx, err) {
+				return nil
+			}
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+// tooManyPackets reports whether the number of read packets exceeds the
+// maximum number of response packets.
+func (c *Conn) tooManyPackets(packets int) bool {
+	return c.settings.maxResponsePackets > 0 && packets > c.settings.maxResponsePackets
+}
+
+// isIdleTimeout reports whether err is caused by exceeded read idle timeout
+// rather than the deadline or ctx.
+func (c *Conn) isIdleTimeout(ctx context.Context, err error) bool {
+	return c.settings.readIdleTimeout > 0 && isTimeout(err) && ctx.Err() == nil
+}
+
+// readNext reads the next packet of multi-packet response. The read idle
+// timeout is used as deadline if it is set.
+func (c *Conn) readNext(ctx context.Context, id int32) (*Packet, error) {
+	if c.settings.readIdleTimeout == 0 {
+		return c.read(ctx, id)
+	}
+
+	if err := c.setReadDeadline(ctx, c.settings.readIdleTimeout); err != nil {
+		return &Packet{}, err
+	}
+
+	return c.readResponse(id)
+}
+
+// read reads structured binary data from c.conn into packet.
+func (c *Conn) read(ctx context.Context, id int32) (*Packet, error) {
+	if err := c.setReadDeadline(ctx, commandTimeout(ctx, c.CurrentTimeout())); err != nil {
+		return &Packet{}, err
+	}
+
+	return c.readResponse(id)
+}
+
+// readResponse reads response packet to request id from c.conn with quirks
+// of known servers.
+func (c *Conn) readResponse(id int32) (*Packet, error) {
+	packet, err := c.readServerPacket(id)
+
+	// Request id -1 is unknown, so keepalives are skipped by the caller.
+	for err == nil && (id != -1 && c.isKeepalive(packet, id) || c.isSentinelTrailer(packet)) {
+		packet, err = c.readServerPacket(id)
+	}
+
+	if err != nil {
+		return packet, err
+	}
+
+	if c.settings.acceptedTypes != nil && !c.acceptsType(packet.Type) {
+		return packet, fmt.Errorf("rcon: %w: %d", ErrInvalidResponseType, packet.Type)
+	}
+
+	return packet, nil
+}
+
+// readServerPacket reads packet from c.conn and works around response ids of
+// Rust and Conan Exiles servers, which don't match request id.
+func (c *Conn) readServerPacket(id int32) (*Packet, error) {
+	packet, err := c.readPacket()
+	if err != nil {
+		return packet, err
+	}
+
+	// Workaround for Rust server.
+	// Rust rcon server responses packet with a type of 4 and the next packet
+	// is valid. It is undocumented, so skip packet and read next unless type 4
+	// is accepted as the response explicitly.
+	if packet.Type == 4 && !c.acceptsType(packet.Type) {
+		if packet, err = c.readPacket(); err != nil {
+			return packet, err
+		}
+
+		// One more workaround for Rust server.
+		// When sent command "Say" there is no response data from server with
+		// packet.ID = request ID, only previous console message that command
+		// was received with packet.ID = -1, therefore, forcibly set packet.ID
+		// to request ID.
+		if packet.ID == -1 {
+			packet.ID = id
+		}
+	}
+
+	// Request id -1 is unknown, so the fixed id is kept as is.
+	if id != -1 && c.isConanExilesID(packet.ID) {
+		packet.ID = id
+	}
+
+	return packet, nil
+}
+
+// isKeepalive reports whether packet is an empty keepalive packet which is
+// skipped by SetSkipEmptyKeepalives instead of the response to request id.
+func (c *Conn) isKeepalive(packet *Packet, id int32) bool {
+	if !c.settings.skipEmptyKeepalives || packet.Type != SERVERDATA_RESPONSE_VALUE || len(packet.body) != 0 {
+		return false
+	}
+
+	return packet.ID != id && (!c.settings.multiPacket || packet.ID != c.settings.sentinelID)
+}
+
+// isSentinelTrailer reports whether packet is the extra packet which SRCDS
+// sends after the mirrored sentinel of multi-packet response. It has the
+// sentinel id and 0x00 0x01 0x00 0x00 in the body field, so it is 2 bytes
+// bigger than the mirrored sentinel. The trailer is skipped when the next
+// response is read, because other servers don't send it.
+func (c *Conn) isSentinelTrailer(packet *Packet) bool {
+	if !c.settings.multiPacket || packet.ID != c.settings.sentinelID || packet.Type != SERVERDATA_RESPONSE_VALUE {
+		return false
+	}
+
+	if packet.Size != MinPacketSize+2 {
+		return false
+	}
+
+	// Lenient padding cuts the body at the first null.
+	return bytes.Equal(packet.body, sentinelTrailer) || len(packet.body) == 0 && c.settings.paddingMode == PaddingLenient
+}
+
+// acceptsType reports whether packetType is accepted by SetAcceptedResponseTypes.
+func (c *Conn) acceptsType(packetType int32) bool {
+	for _, accepted := range c.settings.acceptedTypes {
+		if accepted == packetType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// readPacket reads a packet from c.conn and passes it to the read interceptor.
+func (c *Conn) readPacket() (*Packet, error) {
+	r, dump := c.dumpReader()
+	defer dump()
+
+	if err := c.skipPrefix(r); err != nil {
+		return &Packet{}, err
+	}
+
+	packet := &Packet{}
+	if _, err := packet.readFrom(r, c.settings.byteOrder, c.settings.paddingMode); err != nil {
+		return packet, err
+	}
+
+	packet, err := c.intercept(packet)
+	c.lastPacket.Store(packet.clone())
+
+	return packet, err
+}
+
+// skipPrefix discards the bytes which the server sends before each packet
+// according to SetResponsePrefixSkip.
+func (c *Conn) skipPrefix(r io.Reader) error {
+	if c.settings.responsePrefixSkip <= 0 {
+		return nil
+	}
+
+	if _, err := io.CopyN(io.Discard, r, int64(c.settings.responsePrefixSkip)); err != nil {
+		return fmt.Errorf("rcon: skip response prefix: %w", err)
+	}
+
+	return nil
+}
+
+// readAuthPacket reads auth response packet from c.conn and passes it to the
+// read interceptor. Unlike readPacket it doesn't validate the body padding
+// because servers send various bodies with auth response.
+func (c *Conn) readAuthPacket() (*Packet, error) {
+	r, dump := c.dumpReader()
+	defer dump()
+
+	if err := c.skipPrefix(r); err != nil {
+		return nil, err
+	}
+
+	packet, _, err := readHeader(r, c.settings.byteOrder)
+	if err != nil {
+		return nil, err
+	}
+
+	size := packet.Size - PacketHeaderSize
+	if size < 0 {
+		return nil, fmt.Errorf("rcon: %w: %w", ErrAuthNotRCON, ErrResponseTooSmall)
+	}
+
+	// We must to read response body.
+	packet.body, err = readBody(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("rcon: %w", err)
+	}
+
+	packet.body = bytes.TrimSuffix(packet.body, []byte{0x00, 0x00})
+
+	return c.intercept(&packet)
+}
+
+// intercept passes packet to the read interceptor if it is set.
+func (c *Conn) intercept(packet *Packet) (*Packet, error) {
+	if c.settings.readInterceptor == nil {
+		return packet, nil
+	}
+
+	intercepted, err := c.callInterceptor(c.settings.readInterceptor, *packet)
+	if err != nil {
+		return packet, fmt.Errorf("rcon: read interceptor: %w", err)
+	}
+
+	return &intercepted, nil
+}
+
+// readHeader reads structured binary data without body from r into packet and
+// returns the number of bytes read. Size, ID and Type fields are read at once,
+// so a header cut in the middle returns ErrTruncatedHeader. Only the fields
+// read completely are set then.
+func readHeader(r io.Reader, order binary.ByteOrder) (Packet, int64, error) {
+	var (
+		packet Packet
+		header [4 + PacketHeaderSize]byte
+	)
+
+	n, err := io.ReadFull(r, header[:])
+
+	fields := []*int32{&packet.Size, &packet.ID, &packet.Type}
+	for i := 0; i < n/4; i++ {
+		*fields[i] = int32(order.Uint32(header[i*4:]))
+	}
+
+	if err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return packet, int64(n), fmt.Errorf("rcon: %w: got %d of %d bytes: %w", ErrTruncatedHeader, n, len(header), err)
+		}
+
+		// The error names the field which was being read.
+		return packet, int64(n), fmt.Errorf("rcon: read packet %s: %w", [...]string{"size", "id", "type"}[n/4], err)
+	}
+
+	return packet, int64(n), nil
+}
+
+// nextRequestID returns the request id for the next command.
+func (c *Conn) nextRequestID() (int32, error) {
+	if c.settings.requestIDFunc == nil {
+		for {
+			c.lastID = c.lastID%math.MaxInt32 + 1
+
+			if !c.settings.multiPacket || c.lastID != c.settings.sentinelID {
+				return c.lastID, nil
+			}
+		}
+	}
+
+	id := c.settings.requestIDFunc()
+	if id <= 0 || (c.settings.multiPacket && id == c.settings.sentinelID) {
+		return id, fmt.Errorf("rcon: %w: %d", ErrInvalidRequestID, id)
+	}
+
+	return id, nil
+}
+
+// setReadDeadline sets c.conn read deadline to timeout from now.
+func (c *Conn) setReadDeadline(ctx context.Context, timeout time.Duration) error {
+	return c.setDeadline(ctx, timeout, &c.readDeadline, c.conn.SetReadDeadline)
+}
+
+// setWriteDeadline sets c.conn write deadline to timeout from now.
+func (c *Conn) setWriteDeadline(ctx context.Context, timeout time.Duration) error {
+	return c.setDeadline(ctx, timeout, &c.writeDeadline, c.conn.SetWriteDeadline)
+}
+
+// setDeadline calls set with timeout from now. Non-zero fixed deadline is used
+// instead of timeout unless ctx carries the timeout of ExecuteTimeout. If ctx
+// has an earlier deadline, it is used instead. If there is no deadline at all,
+// set is not called. It returns ctx.Err() if ctx is already done.
+func (c *Conn) setDeadline(
+	ctx context.Context, timeout time.Duration, fixed *time.Time, set func(t time.Time) error,
+) error {
+	c.deadlineMu.Lock()
+	defer c.deadlineMu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var deadline time.Time
+
+	switch {
+	case !fixed.IsZero() && !hasCommandTimeout(ctx):
+		deadline = *fixed
+	case timeout != 0:
+		deadline = time.Now().Add(timeout)
+	}
+
+	if ctxDeadline, ok := ctx.Deadline(); ok && (deadline.IsZero() || ctxDeadline.Before(deadline)) {
+		deadline = ctxDeadline
+	}
+
+	if deadline.IsZero() {
+		return nil
+	}
+
+	if err := set(deadline); err != nil {
+		return fmt.Errorf("rcon: %w", err)
+	}
+
+	return nil
+}
+
+// watchContext interrupts blocked c.conn operations when ctx is done.
+// The returned function stops watching and restores deadlines set from ctx.
+func (c *Conn) watchContext(ctx context.Context) func() {
+	if ctx.Done() == nil {
+		return func() {}
+	}
+
+	interrupted := make(chan struct{})
+
+	stop := context.AfterFunc(ctx, func() {
+		defer close(interrupted)
+
+		c.deadlineMu.Lock()
+		defer c.deadlineMu.Unlock()
+
+		// Set deadline in the past to unblock read or write immediately.
+		_ = c.conn.SetDeadline(time.Unix(1, 0))
+	})
+
+	return func() {
+		if !stop() {
+			<-interrupted
+		}
+
+		c.deadlineMu.Lock()
+		defer c.deadlineMu.Unlock()
+
+		_ = c.conn.SetReadDeadline(c.readDeadline)
+		_ = c.conn.SetWriteDeadline(c.writeDeadline)
+	}
+}
+
+// isTruncated reports whether packet body filled the maximum packet size.
+func isTruncated(packet *Packet) bool {
+	return packet.Size >= MaxPacketSize-MinPacketSize
+}
+
+// contextErr returns ctx.Err() if err is caused by done ctx, otherwise err.
+func contextErr(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		if cause := context.Cause(ctx); cause == ErrCanceled {
+			return cause
+		}
+
+		return ctxErr
+	}
+
+	// Deadline of c.conn may be exceeded a bit earlier than ctx noticed it.
+	if deadline, ok := ctx.Deadline(); ok && isTimeout(err) && !time.Now().Before(deadline) {
+		return context.DeadlineExceeded
+	}
+
+	return err
+}
+
+// isConnError reports whether err is caused by broken connection, so the
+// command may succeed after reconnect.
+func isConnError(err error) bool {
+	var opErr *net.OpError
+
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, net.ErrClosed) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) ||
+		(errors.As(err, &opErr) && !opErr.Timeout())
+}
+
+// sleepContext pauses for d or until ctx is done and returns ctx.Err() then.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// isTimeout reports whether err is caused by exceeded deadline.
+func isTimeout(err error) bool {
+	var netErr net.Error
+
+	return errors.As(err, &netErr) && netErr.Timeout()
 }
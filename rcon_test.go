@@ -2,12 +2,17 @@ package rcon_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
+	"log"
+	"net"
 	"os"
+	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -30,6 +35,16 @@ func authHandler(c *rcontest.Context) {
 		_ = binary.Write(buffer, binary.LittleEndian, c.Request().ID)
 		_ = binary.Write(buffer, binary.LittleEndian, rcon.SERVERDATA_RESPONSE_VALUE)
 
+		buffer.WriteTo(c.Conn())
+	case "undersized":
+		rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, "").WriteTo(c.Conn())
+
+		buffer := bytes.NewBuffer(nil)
+
+		_ = binary.Write(buffer, binary.LittleEndian, int32(4))
+		_ = binary.Write(buffer, binary.LittleEndian, c.Request().ID)
+		_ = binary.Write(buffer, binary.LittleEndian, rcon.SERVERDATA_AUTH_RESPONSE)
+
 		buffer.WriteTo(c.Conn())
 	case c.Server().Settings.Password:
 		rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, "").WriteTo(c.Conn())
@@ -67,6 +82,13 @@ func commandHandler(c *rcontest.Context) {
 		writeWithInvalidPadding(c.Conn(), rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, ""))
 	case "another":
 		rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, 42, "").WriteTo(c.Conn())
+	case "trailing":
+		rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, "ok \r\n\x00\x00").WriteTo(c.Conn())
+	case "disconnect":
+		c.Conn().Close()
+	case "truncated":
+		responseBody := strings.Repeat("a", int(rcon.MaxPacketSize-rcon.MinPacketSize))
+		rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, responseBody).WriteTo(c.Conn())
 	default:
 		rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, "unknown command").WriteTo(c.Conn())
 	}
@@ -141,6 +163,32 @@ func TestDial(t *testing.T) {
 		if !errors.Is(err, rcon.ErrAuthNotRCON) {
 			t.Errorf("got err %q, want %q", err, rcon.ErrAuthNotRCON)
 		}
+
+		if !errors.Is(err, rcon.ErrResponseTooSmall) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrResponseTooSmall)
+		}
+	})
+
+	t.Run("undersized auth response", func(t *testing.T) {
+		server := rcontest.NewServer(
+			rcontest.SetSettings(rcontest.Settings{Password: "undersized"}),
+			rcontest.SetAuthHandler(authHandler),
+		)
+		defer server.Close()
+
+		_, err := rcon.Dial(server.Addr(), "undersized")
+		if !errors.Is(err, rcon.ErrResponseTooSmall) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrResponseTooSmall)
+		}
+	})
+
+	t.Run("tcp keepalive", func(t *testing.T) {
+		conn, err := rcon.Dial(server.Addr(), "password", rcon.SetTCPKeepAlive(30*time.Second, 5*time.Second, 3))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		conn.Close()
 	})
 
 	t.Run("auth success", func(t *testing.T) {
@@ -195,9 +243,8 @@ func TestConn_Execute(t *testing.T) {
 		conn.Close()
 
 		result, err := conn.Execute("help")
-		wantErrMsg := fmt.Sprintf("write tcp %s->%s: use of closed network connection", conn.LocalAddr(), conn.RemoteAddr())
-		if err == nil || err.Error() != wantErrMsg {
-			t.Errorf("got err %q, want to contain %q", err, wantErrMsg)
+		if !errors.Is(err, rcon.ErrConnClosed) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrConnClosed)
 		}
 
 		if len(result) != 0 {
@@ -213,9 +260,8 @@ func TestConn_Execute(t *testing.T) {
 		conn.Close()
 
 		result, err := conn.Execute("help")
-		wantErrMsg := fmt.Sprintf("rcon: set tcp %s: use of closed network connection", conn.LocalAddr())
-		if err == nil || err.Error() != wantErrMsg {
-			t.Errorf("got err %q, want to contain %q", err, wantErrMsg)
+		if !errors.Is(err, rcon.ErrConnClosed) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrConnClosed)
 		}
 
 		if len(result) != 0 {
@@ -296,6 +342,42 @@ func TestConn_Execute(t *testing.T) {
 		}
 	})
 
+	t.Run("last response truncated", func(t *testing.T) {
+		conn, err := rcon.Dial(server.Addr(), "password")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		if _, err := conn.Execute("truncated"); err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if !conn.LastResponseTruncated() {
+			t.Errorf("got truncated %t, want %t", false, true)
+		}
+
+		if _, err := conn.Execute(""); !errors.Is(err, rcon.ErrCommandEmpty) {
+			t.Fatalf("got err %q, want %q", err, rcon.ErrCommandEmpty)
+		}
+
+		if conn.LastResponseTruncated() {
+			t.Errorf("got truncated %t after failed command, want %t", true, false)
+		}
+
+		if _, err := conn.Execute("truncated"); err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if _, err := conn.Execute("help"); err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if conn.LastResponseTruncated() {
+			t.Errorf("got truncated %t, want %t", true, false)
+		}
+	})
+
 	t.Run("rust workaround", func(t *testing.T) {
 		conn, err := rcon.Dial(server.Addr(), "password", rcon.SetDeadline(1*time.Second))
 		if err != nil {
@@ -409,11 +491,2826 @@ func TestConn_Execute(t *testing.T) {
 	}
 }
 
-// getVar returns environment variable or default value.
-func getVar(key string, fallback string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+func TestSetWriteInterceptor(t *testing.T) {
+	server := rcontest.NewServer(rcontest.SetCommandHandler(commandHandler))
+	defer server.Close()
+
+	errRejected := errors.New("rejected")
+
+	conn, err := rcon.Dial(server.Addr(), "", rcon.SetWriteInterceptor(func(p rcon.Packet) (rcon.Packet, error) {
+		switch p.Body() {
+		case "rewrite":
+			return *rcon.NewPacket(p.Type, p.ID, "help"), nil
+		case "reject":
+			return p, errRejected
+		default:
+			return p, nil
+		}
+	}))
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
 	}
+	defer conn.Close()
 
-	return fallback
+	t.Run("rewrite", func(t *testing.T) {
+		result, err := conn.Execute("rewrite")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		resultWant := "lorem ipsum dolor sit amet"
+		if result != resultWant {
+			t.Errorf("got result %q, want %q", result, resultWant)
+		}
+	})
+
+	t.Run("reject", func(t *testing.T) {
+		_, err := conn.Execute("reject")
+		if !errors.Is(err, errRejected) {
+			t.Errorf("got err %q, want %q", err, errRejected)
+		}
+	})
+}
+
+func TestSetReadInterceptor(t *testing.T) {
+	server := rcontest.NewServer(
+		rcontest.SetSettings(rcontest.Settings{Password: "password"}),
+		rcontest.SetCommandHandler(commandHandler),
+	)
+	defer server.Close()
+
+	errRejected := errors.New("rejected")
+
+	t.Run("auth fixup", func(t *testing.T) {
+		conn, err := rcon.Dial(server.Addr(), "wrong", rcon.SetReadInterceptor(func(p rcon.Packet) (rcon.Packet, error) {
+			if p.Type == rcon.SERVERDATA_AUTH_RESPONSE && p.ID == -1 {
+				p.ID = rcon.SERVERDATA_AUTH_ID
+			}
+
+			return p, nil
+		}))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		conn.Close()
+	})
+
+	conn, err := rcon.Dial(server.Addr(), "password", rcon.SetReadInterceptor(func(p rcon.Packet) (rcon.Packet, error) {
+		switch p.Body() {
+		case "lorem ipsum dolor sit amet":
+			return *rcon.NewPacket(p.Type, p.ID, strings.ToUpper(p.Body())), nil
+		case "unknown command":
+			return p, errRejected
+		default:
+			return p, nil
+		}
+	}))
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	t.Run("rewrite", func(t *testing.T) {
+		result, err := conn.Execute("help")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		resultWant := "LOREM IPSUM DOLOR SIT AMET"
+		if result != resultWant {
+			t.Errorf("got result %q, want %q", result, resultWant)
+		}
+	})
+
+	t.Run("reject", func(t *testing.T) {
+		_, err := conn.Execute("whatever")
+		if !errors.Is(err, errRejected) {
+			t.Errorf("got err %q, want %q", err, errRejected)
+		}
+	})
+}
+
+func TestSetMultiPacket(t *testing.T) {
+	multiPacketHandler := func(c *rcontest.Context) {
+		for _, fragment := range []string{"lorem ", "ipsum ", "dolor"} {
+			rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, fragment).WriteTo(c.Conn())
+		}
+	}
+
+	t.Run("sentinel ignored", func(t *testing.T) {
+		server := rcontest.NewServer(
+			rcontest.SetCommandHandler(multiPacketHandler),
+			rcontest.SetMirrorHandler(func(c *rcontest.Context) {}),
+		)
+		defer server.Close()
+
+		conn, err := rcon.Dial(server.Addr(), "", rcon.SetMultiPacket(true), rcon.SetReadIdleTimeout(50*time.Millisecond))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		for i := 0; i < 2; i++ {
+			result, err := conn.Execute("multi")
+			if err != nil {
+				t.Fatalf("got err %q, want %v", err, nil)
+			}
+
+			if result != "lorem ipsum dolor" {
+				t.Errorf("got result %q, want %q", result, "lorem ipsum dolor")
+			}
+		}
+	})
+
+	t.Run("stalled", func(t *testing.T) {
+		server := rcontest.NewServer(
+			rcontest.SetCommandHandler(multiPacketHandler),
+			rcontest.SetMirrorHandler(func(c *rcontest.Context) {}),
+		)
+		defer server.Close()
+
+		conn, err := rcon.Dial(server.Addr(), "", rcon.SetMultiPacket(true), rcon.SetDeadline(100*time.Millisecond))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		if _, err := conn.Execute("multi"); !errors.Is(err, os.ErrDeadlineExceeded) {
+			t.Errorf("got err %q, want %q", err, os.ErrDeadlineExceeded)
+		}
+	})
+
+	t.Run("negative read buffer hint", func(t *testing.T) {
+		server := rcontest.NewServer(rcontest.SetCommandHandler(multiPacketHandler))
+		defer server.Close()
+
+		conn, err := rcon.Dial(server.Addr(), "", rcon.SetMultiPacket(true), rcon.SetReadBufferHint(-1))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		result, err := conn.Execute("multi")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if result != "lorem ipsum dolor" {
+			t.Errorf("got result %q, want %q", result, "lorem ipsum dolor")
+		}
+	})
+
+	t.Run("sentinel", func(t *testing.T) {
+		var sentinelID atomic.Int32
+
+		server := rcontest.NewServer(
+			rcontest.SetCommandHandler(multiPacketHandler),
+			rcontest.SetMirrorHandler(func(c *rcontest.Context) {
+				sentinelID.Store(c.Request().ID)
+				rcontest.MirrorHandler(c)
+			}),
+		)
+		defer server.Close()
+
+		conn, err := rcon.Dial(server.Addr(), "", rcon.SetMultiPacket(true), rcon.SetSentinelID(77))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		result, err := conn.Execute("multi")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		resultWant := "lorem ipsum dolor"
+		if result != resultWant {
+			t.Errorf("got result %q, want %q", result, resultWant)
+		}
+
+		if id := sentinelID.Load(); id != 77 {
+			t.Errorf("got sentinel id %d, want %d", id, 77)
+		}
+	})
+
+	t.Run("srcds trailer", func(t *testing.T) {
+		// Valve wiki sequence: the command response packets, the mirrored
+		// empty packet and the packet with 0x00 0x01 0x00 0x00 body field.
+		server := rcontest.NewServer(
+			rcontest.SetCommandHandler(func(c *rcontest.Context) {
+				if c.Request().Body() == "empty" {
+					rcontest.EmptyHandler(c)
+
+					return
+				}
+
+				multiPacketHandler(c)
+			}),
+			rcontest.SetMirrorHandler(rcontest.SRCDSMirrorHandler),
+		)
+		defer server.Close()
+
+		for _, mode := range []rcon.PaddingMode{rcon.PaddingStrict, rcon.PaddingLenient} {
+			conn, err := rcon.Dial(server.Addr(), "", rcon.SetMultiPacket(true), rcon.SetPaddingMode(mode))
+			if err != nil {
+				t.Fatalf("got err %q, want %v", err, nil)
+			}
+
+			for _, command := range []string{"multi", "empty", "multi"} {
+				result, err := conn.Execute(command)
+				if err != nil {
+					t.Fatalf("got err %q, want %v", err, nil)
+				}
+
+				resultWant := "lorem ipsum dolor"
+				if command == "empty" {
+					resultWant = ""
+				}
+
+				if result != resultWant {
+					t.Errorf("got result %q, want %q", result, resultWant)
+				}
+			}
+
+			conn.Close()
+		}
+	})
+
+	t.Run("too many packets", func(t *testing.T) {
+		server := rcontest.NewServer(
+			rcontest.SetCommandHandler(multiPacketHandler),
+			rcontest.SetMirrorHandler(func(c *rcontest.Context) {}),
+		)
+		defer server.Close()
+
+		conn, err := rcon.Dial(server.Addr(), "", rcon.SetMultiPacket(true), rcon.SetMaxResponsePackets(2))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		result, err := conn.Execute("multi")
+		if !errors.Is(err, rcon.ErrTooManyPackets) {
+			t.Fatalf("got err %q, want %q", err, rcon.ErrTooManyPackets)
+		}
+
+		resultWant := "lorem ipsum "
+		if result != resultWant {
+			t.Errorf("got result %q, want %q", result, resultWant)
+		}
+	})
+
+	t.Run("execute func", func(t *testing.T) {
+		server := rcontest.NewServer(rcontest.SetCommandHandler(multiPacketHandler))
+		defer server.Close()
+
+		conn, err := rcon.Dial(server.Addr(), "", rcon.SetMultiPacket(true))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		var fragments []string
+
+		err = conn.ExecuteFunc("multi", func(body string) error {
+			fragments = append(fragments, body)
+
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		fragmentsWant := []string{"lorem ", "ipsum ", "dolor"}
+		if fmt.Sprint(fragments) != fmt.Sprint(fragmentsWant) {
+			t.Errorf("got fragments %q, want %q", fragments, fragmentsWant)
+		}
+
+		errStop := errors.New("stop")
+		fragments = nil
+
+		err = conn.ExecuteFunc("multi", func(body string) error {
+			fragments = append(fragments, body)
+
+			return errStop
+		})
+		if !errors.Is(err, errStop) {
+			t.Errorf("got err %q, want %q", err, errStop)
+		}
+
+		if len(fragments) != 1 {
+			t.Errorf("got fragments len %d, want %d", len(fragments), 1)
+		}
+	})
+
+	t.Run("idle timeout fallback", func(t *testing.T) {
+		server := rcontest.NewServer(
+			rcontest.SetCommandHandler(multiPacketHandler),
+			rcontest.SetMirrorHandler(func(c *rcontest.Context) {}),
+		)
+		defer server.Close()
+
+		conn, err := rcon.Dial(server.Addr(), "",
+			rcon.SetMultiPacket(true), rcon.SetReadIdleTimeout(100*time.Millisecond))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		result, err := conn.Execute("multi")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		resultWant := "lorem ipsum dolor"
+		if result != resultWant {
+			t.Errorf("got result %q, want %q", result, resultWant)
+		}
+	})
+}
+
+func TestConn_ExecuteContext(t *testing.T) {
+	server := rcontest.NewServer(rcontest.SetSettings(rcontest.Settings{CommandResponseDelay: 500 * time.Millisecond}))
+	defer server.Close()
+
+	conn, err := rcon.Dial(server.Addr(), "")
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := conn.ExecuteContext(ctx, "help")
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("got err %q, want %q", err, context.Canceled)
+		}
+	})
+
+	t.Run("deadline exceeded", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+
+		_, err := conn.ExecuteContext(ctx, "help")
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("got err %q, want %q", err, context.DeadlineExceeded)
+		}
+
+		if took := time.Since(start); took > 400*time.Millisecond {
+			t.Errorf("got duration %s, want less than %s", took, 400*time.Millisecond)
+		}
+	})
+
+	t.Run("success", func(t *testing.T) {
+		conn, err := rcon.Dial(server.Addr(), "")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		if _, err := conn.ExecuteContext(ctx, "help"); err != nil {
+			t.Errorf("got err %q, want %v", err, nil)
+		}
+	})
+}
+
+func TestSetAutoReconnect(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		server := rcontest.NewServer(rcontest.SetCommandHandler(commandHandler))
+		defer server.Close()
+
+		conn, err := rcon.Dial(server.Addr(), "")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		if _, err := conn.Execute("disconnect"); err == nil {
+			t.Errorf("got err %v, want connection error", err)
+		}
+	})
+
+	t.Run("reconnected", func(t *testing.T) {
+		var disconnected atomic.Bool
+
+		server := rcontest.NewServer(rcontest.SetCommandHandler(func(c *rcontest.Context) {
+			if disconnected.CompareAndSwap(false, true) {
+				c.Conn().Close()
+
+				return
+			}
+
+			commandHandler(c)
+		}))
+		defer server.Close()
+
+		conn, err := rcon.Dial(server.Addr(), "", rcon.SetAutoReconnect(3, 10*time.Millisecond))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		result, err := conn.Execute("help")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		resultWant := "lorem ipsum dolor sit amet"
+		if result != resultWant {
+			t.Errorf("got result %q, want %q", result, resultWant)
+		}
+	})
+
+	t.Run("deadline shorter than backoff", func(t *testing.T) {
+		server := rcontest.NewServer(rcontest.SetCommandHandler(commandHandler))
+		defer server.Close()
+
+		conn, err := rcon.Dial(server.Addr(), "", rcon.SetAutoReconnect(3, 5*time.Second))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+
+		_, err = conn.ExecuteContext(ctx, "disconnect")
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("got err %q, want %q", err, context.DeadlineExceeded)
+		}
+
+		if took := time.Since(start); took > 400*time.Millisecond {
+			t.Errorf("got duration %s, want less than %s", took, 400*time.Millisecond)
+		}
+	})
+}
+
+func TestConn_Close(t *testing.T) {
+	server := rcontest.NewServer()
+	defer server.Close()
+
+	conn, err := rcon.Dial(server.Addr(), "")
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	if conn.IsClosed() {
+		t.Errorf("got closed %t, want %t", true, false)
+	}
+
+	if err := conn.Close(); err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	if !conn.IsClosed() {
+		t.Errorf("got closed %t, want %t", false, true)
+	}
+
+	if err := conn.Close(); err != nil {
+		t.Errorf("got err %q, want %v", err, nil)
+	}
+
+	if _, err := conn.ExecuteContext(context.Background(), "help"); !errors.Is(err, rcon.ErrConnClosed) {
+		t.Errorf("got err %q, want %q", err, rcon.ErrConnClosed)
+	}
+
+	if _, err := conn.ExecutePipeline([]string{"help"}); !errors.Is(err, rcon.ErrConnClosed) {
+		t.Errorf("got err %q, want %q", err, rcon.ErrConnClosed)
+	}
+
+	t.Run("interrupted", func(t *testing.T) {
+		server := rcontest.NewServer(rcontest.SetSettings(rcontest.Settings{CommandResponseDelay: time.Second}))
+		defer server.Close()
+
+		conn, err := rcon.Dial(server.Addr(), "")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		time.AfterFunc(100*time.Millisecond, func() { conn.Close() })
+
+		if _, err := conn.Execute("help"); !errors.Is(err, rcon.ErrConnClosed) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrConnClosed)
+		}
+	})
+}
+
+func TestSetAcceptedResponseTypes(t *testing.T) {
+	server := rcontest.NewServer(rcontest.SetCommandHandler(func(c *rcontest.Context) {
+		rcon.NewPacket(7, c.Request().ID, "custom").WriteTo(c.Conn())
+	}))
+	defer server.Close()
+
+	t.Run("accepted", func(t *testing.T) {
+		conn, err := rcon.Dial(server.Addr(), "", rcon.SetAcceptedResponseTypes(rcon.SERVERDATA_RESPONSE_VALUE, 7))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		result, err := conn.Execute("help")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if result != "custom" {
+			t.Errorf("got result %q, want %q", result, "custom")
+		}
+	})
+
+	t.Run("not accepted", func(t *testing.T) {
+		conn, err := rcon.Dial(server.Addr(), "", rcon.SetAcceptedResponseTypes(rcon.SERVERDATA_RESPONSE_VALUE))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		if _, err := conn.Execute("help"); !errors.Is(err, rcon.ErrInvalidResponseType) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrInvalidResponseType)
+		}
+	})
+
+	t.Run("default", func(t *testing.T) {
+		conn, err := rcon.Dial(server.Addr(), "")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		if _, err := conn.Execute("help"); !errors.Is(err, rcon.ErrInvalidResponseType) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrInvalidResponseType)
+		}
+	})
+
+	t.Run("any", func(t *testing.T) {
+		conn, err := rcon.Dial(server.Addr(), "", rcon.SetAcceptedResponseTypes())
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		if result, err := conn.Execute("help"); err != nil || result != "custom" {
+			t.Errorf("got result %q, err %v, want %q", result, err, "custom")
+		}
+	})
+}
+
+func TestConn_Raw(t *testing.T) {
+	server := rcontest.NewServer()
+	defer server.Close()
+
+	conn, err := rcon.Dial(server.Addr(), "")
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	if _, ok := conn.Raw().(*net.TCPConn); !ok {
+		t.Errorf("got raw %T, want %T", conn.Raw(), &net.TCPConn{})
+	}
+
+	if got, want := conn.Raw().LocalAddr(), conn.LocalAddr(); got != want {
+		t.Errorf("got local addr %s, want %s", got, want)
+	}
+}
+
+func TestSetRateLimit(t *testing.T) {
+	server := rcontest.NewServer()
+	defer server.Close()
+
+	t.Run("limit", func(t *testing.T) {
+		conn, err := rcon.Dial(server.Addr(), "", rcon.SetRateLimit(10, 1))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		start := time.Now()
+
+		for i := 0; i < 3; i++ {
+			if _, err := conn.Execute("help"); err != nil {
+				t.Fatalf("got err %q, want %v", err, nil)
+			}
+		}
+
+		if took := time.Since(start); took < 190*time.Millisecond {
+			t.Errorf("got duration %s, want at least %s", took, 200*time.Millisecond)
+		}
+	})
+
+	t.Run("context done while waiting", func(t *testing.T) {
+		conn, err := rcon.Dial(server.Addr(), "", rcon.SetRateLimit(1, 1))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		if _, err := conn.Execute("help"); err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err = conn.ExecuteContext(ctx, "help")
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("got err %q, want %q", err, context.DeadlineExceeded)
+		}
+	})
+}
+
+func TestSetRequestIDFunc(t *testing.T) {
+	ids := make(chan int32, 10)
+
+	server := rcontest.NewServer(rcontest.SetCommandHandler(func(c *rcontest.Context) {
+		ids <- c.Request().ID
+		rcontest.EmptyHandler(c)
+	}))
+	defer server.Close()
+
+	t.Run("default counter", func(t *testing.T) {
+		conn, err := rcon.Dial(server.Addr(), "")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		for want := int32(1); want <= 2; want++ {
+			if _, err := conn.Execute("help"); err != nil {
+				t.Fatalf("got err %q, want %v", err, nil)
+			}
+
+			if got := <-ids; got != want {
+				t.Errorf("got id %d, want %d", got, want)
+			}
+		}
+	})
+
+	t.Run("custom func", func(t *testing.T) {
+		conn, err := rcon.Dial(server.Addr(), "", rcon.SetRequestIDFunc(func() int32 { return 1000 }))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		if _, err := conn.Execute("help"); err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if got := <-ids; got != 1000 {
+			t.Errorf("got id %d, want %d", got, 1000)
+		}
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		conn, err := rcon.Dial(server.Addr(), "", rcon.SetRequestIDFunc(func() int32 { return -5 }))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		_, err = conn.Execute("help")
+		if !errors.Is(err, rcon.ErrInvalidRequestID) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrInvalidRequestID)
+		}
+	})
+}
+
+func TestSetWireDump(t *testing.T) {
+	server := rcontest.NewServer(rcontest.SetCommandHandler(commandHandler))
+	defer server.Close()
+
+	var dump bytes.Buffer
+
+	conn, err := rcon.Dial(server.Addr(), "", rcon.SetWireDump(&dump))
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Execute("help"); err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	for _, want := range []string{
+		"write 14 bytes\n",
+		"write 18 bytes\n",
+		"|............help|",
+		"read 40 bytes\n",
+		"|m ipsum dolor si|",
+	} {
+		if !strings.Contains(dump.String(), want) {
+			t.Errorf("got dump %q, want to contain %q", dump.String(), want)
+		}
+	}
+}
+
+func TestConn_SetDeadline(t *testing.T) {
+	server := rcontest.NewServer(rcontest.SetSettings(rcontest.Settings{CommandResponseDelay: 200 * time.Millisecond}))
+	defer server.Close()
+
+	t.Run("loosen", func(t *testing.T) {
+		conn, err := rcon.Dial(server.Addr(), "", rcon.SetDeadline(100*time.Millisecond))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		if err := conn.SetDeadline(time.Now().Add(2 * time.Second)); err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if _, err := conn.Execute("help"); err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if err := conn.SetDeadline(time.Time{}); err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		_, err = conn.Execute("help")
+		if err == nil || !strings.Contains(err.Error(), "i/o timeout") {
+			t.Errorf("got err %q, want to contain %q", err, "i/o timeout")
+		}
+	})
+
+	t.Run("tighten", func(t *testing.T) {
+		conn, err := rcon.Dial(server.Addr(), "")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		if err := conn.SetReadDeadline(time.Now().Add(50 * time.Millisecond)); err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		_, err = conn.Execute("help")
+		if err == nil || !strings.Contains(err.Error(), "i/o timeout") {
+			t.Errorf("got err %q, want to contain %q", err, "i/o timeout")
+		}
+	})
+}
+
+func TestSetTrimResponse(t *testing.T) {
+	server := rcontest.NewServer(rcontest.SetCommandHandler(commandHandler))
+	defer server.Close()
+
+	conn, err := rcon.Dial(server.Addr(), "", rcon.SetTrimResponse(true))
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	result, err := conn.Execute("trailing")
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	if result != "ok" {
+		t.Errorf("got result %q, want %q", result, "ok")
+	}
+
+	raw, err := conn.ExecuteBytes("trailing")
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	if rawWant := []byte("ok \r\n\x00\x00"); !bytes.Equal(raw, rawWant) {
+		t.Errorf("got raw %q, want %q", raw, rawWant)
+	}
+}
+
+func TestConn_TryExecute(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	server := rcontest.NewServer(rcontest.SetCommandHandler(func(c *rcontest.Context) {
+		if c.Request().Body() == "slow" {
+			close(started)
+			<-release
+		}
+
+		rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, c.Request().Body()).WriteTo(c.Conn())
+	}))
+	defer server.Close()
+
+	conn, err := rcon.Dial(server.Addr(), "")
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	done := make(chan error)
+	go func() {
+		_, err := conn.Execute("slow")
+		done <- err
+	}()
+
+	<-started
+
+	result, ok, err := conn.TryExecute("fast")
+	if ok || err != nil || result != "" {
+		t.Errorf("got (%q, %t, %v), want (%q, %t, %v)", result, ok, err, "", false, nil)
+	}
+
+	close(release)
+
+	if err := <-done; err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	result, ok, err = conn.TryExecute("fast")
+	if !ok || err != nil || result != "fast" {
+		t.Errorf("got (%q, %t, %v), want (%q, %t, %v)", result, ok, err, "fast", true, nil)
+	}
+}
+
+func BenchmarkConn_Execute_multiPacket(b *testing.B) {
+	const fragments = 64
+
+	fragment := strings.Repeat("a", int(rcon.MaxPacketSize-rcon.MinPacketSize))
+
+	server := rcontest.NewServer(rcontest.SetCommandHandler(func(c *rcontest.Context) {
+		for i := 0; i < fragments; i++ {
+			rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, fragment).WriteTo(c.Conn())
+		}
+	}))
+	defer server.Close()
+
+	for name, hint := range map[string]int{"no hint": 0, "hint": fragments * len(fragment)} {
+		b.Run(name, func(b *testing.B) {
+			conn, err := rcon.Dial(server.Addr(), "", rcon.SetMultiPacket(true), rcon.SetReadBufferHint(hint))
+			if err != nil {
+				b.Fatalf("got err %q, want %v", err, nil)
+			}
+			defer conn.Close()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				if _, err := conn.Execute("dump"); err != nil {
+					b.Fatalf("got err %q, want %v", err, nil)
+				}
+			}
+		})
+	}
+}
+
+// getVar returns environment variable or default value.
+func getVar(key string, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+
+	return fallback
+}
+
+func TestConn_ExecutePipeline(t *testing.T) {
+	t.Run("single packet", func(t *testing.T) {
+		server := rcontest.NewServer(rcontest.SetCommandHandler(commandHandler))
+		defer server.Close()
+
+		conn, err := rcon.Dial(server.Addr(), "")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		results, err := conn.ExecutePipeline([]string{"help", "trailing", "status"})
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		resultsWant := []string{"lorem ipsum dolor sit amet", "ok \r\n\x00\x00", "unknown command"}
+		if fmt.Sprint(results) != fmt.Sprint(resultsWant) {
+			t.Errorf("got results %q, want %q", results, resultsWant)
+		}
+	})
+
+	t.Run("multi packet", func(t *testing.T) {
+		server := rcontest.NewServer(rcontest.SetCommandHandler(func(c *rcontest.Context) {
+			for _, fragment := range []string{c.Request().Body(), " done"} {
+				rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, fragment).WriteTo(c.Conn())
+			}
+		}))
+		defer server.Close()
+
+		conn, err := rcon.Dial(server.Addr(), "", rcon.SetMultiPacket(true))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		results, err := conn.ExecutePipeline([]string{"first", "second"})
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		resultsWant := []string{"first done", "second done"}
+		if fmt.Sprint(results) != fmt.Sprint(resultsWant) {
+			t.Errorf("got results %q, want %q", results, resultsWant)
+		}
+	})
+
+	t.Run("max packets per response", func(t *testing.T) {
+		server := rcontest.NewServer(rcontest.SetCommandHandler(func(c *rcontest.Context) {
+			for _, fragment := range []string{c.Request().Body(), " done"} {
+				rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, fragment).WriteTo(c.Conn())
+			}
+		}))
+		defer server.Close()
+
+		conn, err := rcon.Dial(server.Addr(), "", rcon.SetMultiPacket(true), rcon.SetMaxResponsePackets(2))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		if _, err := conn.ExecutePipeline([]string{"first", "second", "third"}); err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		conn, err = rcon.Dial(server.Addr(), "", rcon.SetMultiPacket(true), rcon.SetMaxResponsePackets(1))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		if _, err := conn.ExecutePipeline([]string{"first", "second"}); !errors.Is(err, rcon.ErrTooManyPackets) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrTooManyPackets)
+		}
+	})
+
+	t.Run("fixed ids", func(t *testing.T) {
+		echo := rcontest.SetCommandHandler(func(c *rcontest.Context) {
+			id := c.Request().ID
+			if c.Request().Body() == "say" {
+				id = -1
+			}
+
+			rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, id, c.Request().Body()).WriteTo(c.Conn())
+		})
+
+		profiles := []struct {
+			profile  rcontest.GameProfile
+			game     rcon.GameType
+			commands []string
+		}{
+			{rcontest.ConanProfile, rcon.GameConanExiles, []string{"first", "second", "third"}},
+			{rcontest.RustProfile, rcon.GameRust, []string{"first", "say", "third"}},
+		}
+
+		for _, tt := range profiles {
+			server := rcontest.NewServer(rcontest.SetGameProfile(tt.profile), echo)
+
+			conn, err := rcon.Dial(server.Addr(), "", rcon.SetGameType(tt.game))
+			if err != nil {
+				t.Fatalf("got err %q, want %v", err, nil)
+			}
+
+			results, err := conn.ExecutePipeline(tt.commands)
+			if err != nil {
+				t.Errorf("%s: got err %q, want %v", tt.game, err, nil)
+			}
+
+			if fmt.Sprint(results) != fmt.Sprint(tt.commands) {
+				t.Errorf("%s: got results %q, want %q", tt.game, results, tt.commands)
+			}
+
+			conn.Close()
+			server.Close()
+		}
+
+		server := rcontest.NewServer(rcontest.SetGameProfile(rcontest.ConanProfile), echo)
+		defer server.Close()
+
+		conn, err := rcon.Dial(server.Addr(), "", rcon.SetGameType(rcon.GameConanExiles), rcon.SetMultiPacket(true))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		if _, err := conn.ExecutePipeline([]string{"first"}); !errors.Is(err, rcon.ErrPipelineUnsupported) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrPipelineUnsupported)
+		}
+	})
+
+	t.Run("unknown id", func(t *testing.T) {
+		server := rcontest.NewServer(rcontest.SetCommandHandler(commandHandler))
+		defer server.Close()
+
+		conn, err := rcon.Dial(server.Addr(), "")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		results, err := conn.ExecutePipeline([]string{"help", "another"})
+		if !errors.Is(err, rcon.ErrInvalidPacketID) {
+			t.Fatalf("got err %q, want %q", err, rcon.ErrInvalidPacketID)
+		}
+
+		if results[0] != "lorem ipsum dolor sit amet" {
+			t.Errorf("got result %q, want %q", results[0], "lorem ipsum dolor sit amet")
+		}
+	})
+}
+
+func TestDialAsync(t *testing.T) {
+	server := rcontest.NewServer(rcontest.SetSettings(rcontest.Settings{Password: "password"}))
+	defer server.Close()
+
+	t.Run("success", func(t *testing.T) {
+		results := rcon.DialAsync(server.Addr(), "password")
+
+		result := <-results
+		if result.Err != nil {
+			t.Fatalf("got err %q, want %v", result.Err, nil)
+		}
+		defer result.Conn.Close()
+
+		if _, ok := <-results; ok {
+			t.Errorf("got open channel, want closed after result")
+		}
+	})
+
+	t.Run("authentication failed", func(t *testing.T) {
+		result := <-rcon.DialAsync(server.Addr(), "wrong")
+		if !errors.Is(result.Err, rcon.ErrAuthFailed) {
+			t.Errorf("got err %q, want %q", result.Err, rcon.ErrAuthFailed)
+		}
+	})
+
+	t.Run("context deadline exceeded", func(t *testing.T) {
+		server := rcontest.NewServer(rcontest.SetSettings(rcontest.Settings{Password: "password", AuthResponseDelay: time.Second}))
+		defer server.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+
+		result := <-rcon.DialAsyncContext(ctx, server.Addr(), "password")
+		if !errors.Is(result.Err, context.DeadlineExceeded) {
+			t.Errorf("got err %q, want %q", result.Err, context.DeadlineExceeded)
+		}
+
+		if took := time.Since(start); took > 400*time.Millisecond {
+			t.Errorf("got duration %s, want less than %s", took, 400*time.Millisecond)
+		}
+	})
+}
+
+type recordingObserver struct {
+	connects []rcon.ConnectEvent
+	commands []rcon.CommandEvent
+	closes   []rcon.CloseEvent
+}
+
+func (o *recordingObserver) OnConnect(event rcon.ConnectEvent) {
+	o.connects = append(o.connects, event)
+}
+
+func (o *recordingObserver) OnCommand(event rcon.CommandEvent) {
+	o.commands = append(o.commands, event)
+}
+
+func (o *recordingObserver) OnClose(event rcon.CloseEvent) {
+	o.closes = append(o.closes, event)
+}
+
+func TestSetObserver_authFailed(t *testing.T) {
+	server := rcontest.NewServer(rcontest.SetSettings(rcontest.Settings{Password: "password"}))
+	defer server.Close()
+
+	observer := &recordingObserver{}
+
+	if _, err := rcon.Dial(server.Addr(), "wrong", rcon.SetObserver(observer)); !errors.Is(err, rcon.ErrAuthFailed) {
+		t.Fatalf("got err %q, want %q", err, rcon.ErrAuthFailed)
+	}
+
+	if len(observer.connects) != 1 || !errors.Is(observer.connects[0].Err, rcon.ErrAuthFailed) {
+		t.Errorf("got connect events %+v, want one failed", observer.connects)
+	}
+
+	if len(observer.closes) != 0 {
+		t.Errorf("got close events len %d, want %d", len(observer.closes), 0)
+	}
+
+	if _, err := rcon.DialAndExecute(server.Addr(), "wrong", "help", rcon.SetObserver(observer)); !errors.Is(err, rcon.ErrAuthFailed) {
+		t.Fatalf("got err %q, want %q", err, rcon.ErrAuthFailed)
+	}
+
+	if len(observer.connects) != 2 || len(observer.closes) != 0 {
+		t.Errorf("got connect events len %d and close events len %d, want %d and %d",
+			len(observer.connects), len(observer.closes), 2, 0)
+	}
+}
+
+func TestSetObserver_timing(t *testing.T) {
+	server := rcontest.NewServer(rcontest.SetSettings(rcontest.Settings{AuthResponseDelay: 100 * time.Millisecond}))
+	defer server.Close()
+
+	observer := &recordingObserver{}
+
+	conn, err := rcon.Dial(server.Addr(), "", rcon.SetObserver(observer))
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	if timing := observer.connects[0].Timing; timing.Auth < 100*time.Millisecond || timing.Dial >= timing.Auth {
+		t.Errorf("got timing %+v, want auth at least %s and longer than dial", timing, 100*time.Millisecond)
+	}
+}
+
+func TestSetObserver(t *testing.T) {
+	server := rcontest.NewServer(rcontest.SetCommandHandler(commandHandler))
+	defer server.Close()
+
+	observer := &recordingObserver{}
+
+	conn, err := rcon.Dial(server.Addr(), "", rcon.SetObserver(observer))
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	if len(observer.connects) != 1 || observer.connects[0].Address != server.Addr() || observer.connects[0].Err != nil {
+		t.Errorf("got connect events %+v, want one successful to %s", observer.connects, server.Addr())
+	}
+
+	if timing := observer.connects[0].Timing; timing.Dial+timing.Auth > observer.connects[0].Duration {
+		t.Errorf("got timing %+v, want sum less than duration %s", timing, observer.connects[0].Duration)
+	}
+
+	if _, err := conn.Execute("help"); err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	if _, err := conn.ExecuteTagged("another", "category=admin"); !errors.Is(err, rcon.ErrInvalidPacketID) {
+		t.Fatalf("got err %q, want %q", err, rcon.ErrInvalidPacketID)
+	}
+
+	conn.Close()
+	conn.Close()
+
+	if len(observer.commands) != 2 {
+		t.Fatalf("got command events len %d, want %d", len(observer.commands), 2)
+	}
+
+	if event := observer.commands[0]; event.Command != "help" || event.Tag != "" ||
+		event.ResponseSize != len("lorem ipsum dolor sit amet") || event.Err != nil {
+		t.Errorf("got command event %+v, want successful help without tag", event)
+	}
+
+	if event := observer.commands[1]; event.Tag != "category=admin" || !errors.Is(event.Err, rcon.ErrInvalidPacketID) {
+		t.Errorf("got command event %+v, want failed another with tag", event)
+	}
+
+	if len(observer.closes) != 1 {
+		t.Errorf("got close events len %d, want %d", len(observer.closes), 1)
+	}
+}
+
+func TestSetBanPatterns(t *testing.T) {
+	server := rcontest.NewServer(rcontest.SetCommandHandler(func(c *rcontest.Context) {
+		rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, "Go away, "+c.Request().Body()).WriteTo(c.Conn())
+	}))
+	defer server.Close()
+
+	t.Run("auth", func(t *testing.T) {
+		server := rcontest.NewServer(rcontest.SetAuthHandler(func(c *rcontest.Context) {
+			rcon.NewPacket(rcon.SERVERDATA_AUTH_RESPONSE, -1, "You have been BANNED").WriteTo(c.Conn())
+		}))
+		defer server.Close()
+
+		if _, err := rcon.Dial(server.Addr(), ""); !errors.Is(err, rcon.ErrBanned) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrBanned)
+		}
+	})
+
+	t.Run("game auth", func(t *testing.T) {
+		server := rcontest.NewServer(rcontest.SetAuthHandler(func(c *rcontest.Context) {
+			rcon.NewPacket(rcon.SERVERDATA_AUTH_RESPONSE, -1, "Your IP address is banned").WriteTo(c.Conn())
+		}))
+		defer server.Close()
+
+		if _, err := rcon.Dial(server.Addr(), "", rcon.SetGameType(rcon.GameMinecraft)); !errors.Is(err, rcon.ErrBanned) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrBanned)
+		}
+
+		if _, err := rcon.Dial(server.Addr(), ""); errors.Is(err, rcon.ErrBanned) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrAuthFailed)
+		}
+	})
+
+	t.Run("command response", func(t *testing.T) {
+		conn, err := rcon.Dial(server.Addr(), "")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		result, err := conn.Execute("banid 1 you are banned")
+		if err != nil {
+			t.Errorf("got err %q, want %v", err, nil)
+		}
+
+		if result != "Go away, banid 1 you are banned" {
+			t.Errorf("got result %q, want %q", result, "Go away, banid 1 you are banned")
+		}
+	})
+
+	t.Run("disconnect", func(t *testing.T) {
+		server := rcontest.NewServer(rcontest.SetCommandHandler(func(c *rcontest.Context) {
+			rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, "You have been banned").WriteTo(c.Conn())
+			c.Conn().Close()
+		}))
+		defer server.Close()
+
+		conn, err := rcon.Dial(server.Addr(), "", rcon.SetMultiPacket(true))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		if _, err := conn.Execute("status"); !errors.Is(err, rcon.ErrBanned) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrBanned)
+		}
+	})
+
+	t.Run("custom patterns", func(t *testing.T) {
+		conn, err := rcon.Dial(server.Addr(), "", rcon.SetBanPatterns([]string{"go away"}))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		result, err := conn.Execute("status")
+		if !errors.Is(err, rcon.ErrBanned) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrBanned)
+		}
+
+		if result != "Go away, status" {
+			t.Errorf("got result %q, want %q", result, "Go away, status")
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		conn, err := rcon.Dial(server.Addr(), "", rcon.SetBanPatterns(nil))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		if _, err := conn.Execute("you are banned"); err != nil {
+			t.Errorf("got err %q, want %v", err, nil)
+		}
+	})
+}
+
+func TestBanPatterns(t *testing.T) {
+	patterns := rcon.BanPatterns(rcon.GameMinecraft)
+	if len(patterns) <= len(rcon.DefaultBanPatterns) {
+		t.Fatalf("got patterns len %d, want more than %d", len(patterns), len(rcon.DefaultBanPatterns))
+	}
+
+	if patterns[0] != rcon.DefaultBanPatterns[0] {
+		t.Errorf("got pattern %q, want %q", patterns[0], rcon.DefaultBanPatterns[0])
+	}
+
+	if got := rcon.BanPatterns(rcon.GameUnknown); len(got) != len(rcon.DefaultBanPatterns) {
+		t.Errorf("got patterns len %d, want %d", len(got), len(rcon.DefaultBanPatterns))
+	}
+}
+
+func TestSetAuthOrder(t *testing.T) {
+	orders := []struct {
+		name  string
+		order rcon.AuthOrder
+	}{
+		{"empty then auth", rcon.EmptyThenAuth},
+		{"auth then empty", rcon.AuthThenEmpty},
+		{"auth only", rcon.AuthOnly},
+	}
+
+	for _, tt := range orders {
+		t.Run(tt.name, func(t *testing.T) {
+			server := rcontest.NewServer(
+				rcontest.SetSettings(rcontest.Settings{Password: "password", AuthOrder: tt.order}),
+				rcontest.SetCommandHandler(commandHandler),
+			)
+			defer server.Close()
+
+			conn, err := rcon.Dial(server.Addr(), "password", rcon.SetAuthOrder(tt.order))
+			if err != nil {
+				t.Fatalf("got err %q, want %v", err, nil)
+			}
+			defer conn.Close()
+
+			result, err := conn.Execute("help")
+			if err != nil {
+				t.Fatalf("got err %q, want %v", err, nil)
+			}
+
+			if result != "lorem ipsum dolor sit amet" {
+				t.Errorf("got result %q, want %q", result, "lorem ipsum dolor sit amet")
+			}
+
+			if _, err := rcon.Dial(server.Addr(), "wrong", rcon.SetAuthOrder(tt.order)); !errors.Is(err, rcon.ErrAuthFailed) {
+				t.Errorf("got err %q, want %q", err, rcon.ErrAuthFailed)
+			}
+		})
+	}
+
+	t.Run("misinterpreted order", func(t *testing.T) {
+		server := rcontest.NewServer(
+			rcontest.SetSettings(rcontest.Settings{Password: "password", AuthOrder: rcon.AuthThenEmpty}),
+			rcontest.SetCommandHandler(commandHandler),
+		)
+		defer server.Close()
+
+		conn, err := rcon.Dial(server.Addr(), "password")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		// The empty packet after auth response is read as the command response.
+		if _, err := conn.Execute("help"); !errors.Is(err, rcon.ErrInvalidPacketID) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrInvalidPacketID)
+		}
+	})
+}
+
+func TestSetConnStateFunc(t *testing.T) {
+	server := rcontest.NewServer(
+		rcontest.SetSettings(rcontest.Settings{Password: "password"}),
+		rcontest.SetCommandHandler(commandHandler),
+	)
+	defer server.Close()
+
+	var transitions []string
+
+	stateFunc := func(from rcon.ConnState, to rcon.ConnState) {
+		transitions = append(transitions, from.String()+"->"+to.String())
+	}
+
+	t.Run("lifecycle", func(t *testing.T) {
+		transitions = nil
+
+		conn, err := rcon.Dial(server.Addr(), "password", rcon.SetConnStateFunc(stateFunc))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if _, err := conn.Execute("help"); err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if _, err := conn.Execute("another"); !errors.Is(err, rcon.ErrInvalidPacketID) {
+			t.Fatalf("got err %q, want %q", err, rcon.ErrInvalidPacketID)
+		}
+
+		conn.Close()
+
+		if conn.State() != rcon.StateClosed {
+			t.Errorf("got state %s, want %s", conn.State(), rcon.StateClosed)
+		}
+
+		transitionsWant := []string{
+			"new->connecting", "connecting->authenticated",
+			"authenticated->executing", "executing->authenticated",
+			"authenticated->executing", "executing->errored",
+			"errored->closed",
+		}
+		if fmt.Sprint(transitions) != fmt.Sprint(transitionsWant) {
+			t.Errorf("got transitions %q, want %q", transitions, transitionsWant)
+		}
+	})
+
+	t.Run("authentication failed", func(t *testing.T) {
+		transitions = nil
+
+		if _, err := rcon.Dial(server.Addr(), "wrong", rcon.SetConnStateFunc(stateFunc)); !errors.Is(err, rcon.ErrAuthFailed) {
+			t.Fatalf("got err %q, want %q", err, rcon.ErrAuthFailed)
+		}
+
+		transitionsWant := []string{"new->connecting", "connecting->errored", "errored->closed"}
+		if fmt.Sprint(transitions) != fmt.Sprint(transitionsWant) {
+			t.Errorf("got transitions %q, want %q", transitions, transitionsWant)
+		}
+	})
+}
+
+func TestSetAppendNewline(t *testing.T) {
+	server := rcontest.NewServer(rcontest.SetCommandHandler(func(c *rcontest.Context) {
+		rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, c.Request().Body()).WriteTo(c.Conn())
+	}))
+	defer server.Close()
+
+	conn, err := rcon.Dial(server.Addr(), "", rcon.SetAppendNewline(true))
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	result, err := conn.Execute("help")
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	if result != "help\n" {
+		t.Errorf("got result %q, want %q", result, "help\n")
+	}
+
+	if _, err := conn.Execute(strings.Repeat("a", rcon.MaxCommandLen)); !errors.Is(err, rcon.ErrCommandTooLong) {
+		t.Errorf("got err %q, want %q", err, rcon.ErrCommandTooLong)
+	}
+}
+
+func TestConn_Stats(t *testing.T) {
+	server := rcontest.NewServer(
+		rcontest.SetSettings(rcontest.Settings{CommandResponseDelay: 10 * time.Millisecond}),
+		rcontest.SetCommandHandler(commandHandler),
+	)
+	defer server.Close()
+
+	conn, err := rcon.Dial(server.Addr(), "")
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Execute("help"); err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	if _, err := conn.Execute("another"); !errors.Is(err, rcon.ErrInvalidPacketID) {
+		t.Fatalf("got err %q, want %q", err, rcon.ErrInvalidPacketID)
+	}
+
+	stats := conn.Stats()
+
+	if stats.Commands != 2 || stats.Errors != 1 {
+		t.Errorf("got commands %d and errors %d, want %d and %d", stats.Commands, stats.Errors, 2, 1)
+	}
+
+	// Auth request with empty password and two commands of 4 and 7 bytes.
+	if bytesOut := int64(14 + 18 + 21); stats.BytesOut != bytesOut {
+		t.Errorf("got bytes out %d, want %d", stats.BytesOut, bytesOut)
+	}
+
+	// Two auth response packets, help response and empty response.
+	if bytesIn := int64(14 + 14 + 40 + 14); stats.BytesIn != bytesIn {
+		t.Errorf("got bytes in %d, want %d", stats.BytesIn, bytesIn)
+	}
+
+	if stats.AvgLatency < 10*time.Millisecond || stats.MaxLatency < stats.AvgLatency {
+		t.Errorf("got avg latency %s and max %s, want at least %s", stats.AvgLatency, stats.MaxLatency, 10*time.Millisecond)
+	}
+
+	if stats.Uptime < 20*time.Millisecond {
+		t.Errorf("got uptime %s, want at least %s", stats.Uptime, 20*time.Millisecond)
+	}
+}
+
+func TestSetKeepOpenOnAuthFail(t *testing.T) {
+	server := rcontest.NewServer(rcontest.SetSettings(rcontest.Settings{Password: "password"}))
+	defer server.Close()
+
+	t.Run("default", func(t *testing.T) {
+		conn, err := rcon.Dial(server.Addr(), "wrong")
+		if !errors.Is(err, rcon.ErrAuthFailed) {
+			t.Fatalf("got err %q, want %q", err, rcon.ErrAuthFailed)
+		}
+
+		if !conn.IsClosed() {
+			t.Error("got open connection, want closed")
+		}
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		conn, err := rcon.Dial(server.Addr(), "wrong", rcon.SetKeepOpenOnAuthFail(true))
+		if !errors.Is(err, rcon.ErrAuthFailed) {
+			t.Fatalf("got err %q, want %q", err, rcon.ErrAuthFailed)
+		}
+		defer conn.Close()
+
+		if conn.IsClosed() {
+			t.Error("got closed connection, want open")
+		}
+
+		if err := conn.Raw().SetDeadline(time.Now().Add(time.Second)); err != nil {
+			t.Errorf("got err %q, want %v", err, nil)
+		}
+	})
+}
+
+func TestConn_Execute_notAuthenticated(t *testing.T) {
+	t.Run("zero conn", func(t *testing.T) {
+		var conn rcon.Conn
+
+		if _, err := conn.Execute("help"); !errors.Is(err, rcon.ErrNotAuthenticated) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrNotAuthenticated)
+		}
+	})
+
+	t.Run("auth failed", func(t *testing.T) {
+		server := rcontest.NewServer(rcontest.SetSettings(rcontest.Settings{Password: "password"}))
+		defer server.Close()
+
+		conn, err := rcon.Dial(server.Addr(), "wrong", rcon.SetKeepOpenOnAuthFail(true))
+		if !errors.Is(err, rcon.ErrAuthFailed) {
+			t.Fatalf("got err %q, want %q", err, rcon.ErrAuthFailed)
+		}
+		defer conn.Close()
+
+		if _, err := conn.Execute("help"); !errors.Is(err, rcon.ErrNotAuthenticated) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrNotAuthenticated)
+		}
+	})
+}
+
+func TestSetSkipEmptyKeepalives(t *testing.T) {
+	server := rcontest.NewServer(rcontest.SetCommandHandler(func(c *rcontest.Context) {
+		rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, 0, "").WriteTo(c.Conn())
+		rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, 0, "").WriteTo(c.Conn())
+		rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, "ok").WriteTo(c.Conn())
+	}))
+	defer server.Close()
+
+	t.Run("disabled", func(t *testing.T) {
+		conn, err := rcon.Dial(server.Addr(), "")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		if _, err := conn.Execute("help"); !errors.Is(err, rcon.ErrInvalidPacketID) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrInvalidPacketID)
+		}
+	})
+
+	for _, multiPacket := range []bool{false, true} {
+		t.Run(fmt.Sprintf("multi packet %t", multiPacket), func(t *testing.T) {
+			conn, err := rcon.Dial(server.Addr(), "", rcon.SetSkipEmptyKeepalives(true), rcon.SetMultiPacket(multiPacket))
+			if err != nil {
+				t.Fatalf("got err %q, want %v", err, nil)
+			}
+			defer conn.Close()
+
+			for i := 0; i < 2; i++ {
+				result, err := conn.Execute("help")
+				if err != nil {
+					t.Fatalf("got err %q, want %v", err, nil)
+				}
+
+				if result != "ok" {
+					t.Errorf("got result %q, want %q", result, "ok")
+				}
+			}
+		})
+	}
+}
+
+func TestConn_ExecuteExpect(t *testing.T) {
+	server := rcontest.NewServer(rcontest.SetCommandHandler(commandHandler))
+	defer server.Close()
+
+	conn, err := rcon.Dial(server.Addr(), "")
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	t.Run("expected", func(t *testing.T) {
+		result, err := conn.ExecuteExpect("help", "ipsum")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if result != "lorem ipsum dolor sit amet" {
+			t.Errorf("got result %q, want %q", result, "lorem ipsum dolor sit amet")
+		}
+	})
+
+	t.Run("unexpected", func(t *testing.T) {
+		result, err := conn.ExecuteExpect("kick Steve", "Kicked")
+		if !errors.Is(err, rcon.ErrUnexpectedResponse) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrUnexpectedResponse)
+		}
+
+		if result != "unknown command" {
+			t.Errorf("got result %q, want %q", result, "unknown command")
+		}
+	})
+}
+
+func TestConn_Execute_truncatedHeader(t *testing.T) {
+	server := rcontest.NewServer(rcontest.SetCommandHandler(func(c *rcontest.Context) {
+		var buffer bytes.Buffer
+
+		rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, "ok").WriteTo(&buffer)
+		c.Conn().Write(buffer.Bytes()[:6])
+		c.Conn().Close()
+	}))
+	defer server.Close()
+
+	conn, err := rcon.Dial(server.Addr(), "")
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	_, err = conn.Execute("status")
+	if !errors.Is(err, rcon.ErrTruncatedHeader) {
+		t.Errorf("got err %q, want %q", err, rcon.ErrTruncatedHeader)
+	}
+
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("got err %q, want %q", err, io.ErrUnexpectedEOF)
+	}
+}
+
+func TestConn_ExecuteRaw(t *testing.T) {
+	server := rcontest.NewServer(rcontest.SetCommandHandler(func(c *rcontest.Context) {
+		rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, c.Request().Body()).WriteTo(c.Conn())
+	}))
+	defer server.Close()
+
+	conn, err := rcon.Dial(server.Addr(), "")
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	t.Run("binary body", func(t *testing.T) {
+		body := []byte{0xff, 0x00, 'g', 'o', 0xfe}
+
+		raw, err := conn.ExecuteRaw(body)
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if !bytes.Equal(raw, body) {
+			t.Errorf("got raw %q, want %q", raw, body)
+		}
+	})
+
+	t.Run("too long", func(t *testing.T) {
+		_, err := conn.ExecuteRaw(make([]byte, rcon.MaxCommandLen+1))
+		if !errors.Is(err, rcon.ErrCommandTooLong) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrCommandTooLong)
+		}
+	})
+
+	t.Run("command options", func(t *testing.T) {
+		conn, err := rcon.Dial(server.Addr(), "",
+			rcon.SetAppendNewline(true),
+			rcon.SetCommandPipeline(func(command string) (string, error) { return "/" + command, nil }),
+			rcon.SetTranscoder(caseTranscoder{}),
+		)
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		body := []byte{0x01, 0x02}
+
+		raw, err := conn.ExecuteRaw(body)
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if !bytes.Equal(raw, body) {
+			t.Errorf("got raw %q, want %q", raw, body)
+		}
+	})
+}
+
+// caseTranscoder is a test transcoder with upper case charset of the server.
+type caseTranscoder struct{}
+
+func (caseTranscoder) Encode(command string) (string, error) {
+	if strings.ContainsAny(command, "!") {
+		return "", errors.New("unsupported character")
+	}
+
+	return strings.ToUpper(command), nil
+}
+
+func (caseTranscoder) Decode(response string) (string, error) {
+	return strings.ToLower(response), nil
+}
+
+func TestSetTranscoder(t *testing.T) {
+	server := rcontest.NewServer(rcontest.SetCommandHandler(func(c *rcontest.Context) {
+		rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, c.Request().Body()+" OK").WriteTo(c.Conn())
+	}))
+	defer server.Close()
+
+	conn, err := rcon.Dial(server.Addr(), "", rcon.SetTranscoder(caseTranscoder{}))
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	result, err := conn.Execute("say hello")
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	if result != "say hello ok" {
+		t.Errorf("got result %q, want %q", result, "say hello ok")
+	}
+
+	raw, err := conn.ExecuteBytes("say hello")
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	if string(raw) != "SAY HELLO OK" {
+		t.Errorf("got raw %q, want %q", raw, "SAY HELLO OK")
+	}
+
+	if _, err := conn.Execute("say hello!"); err == nil {
+		t.Errorf("got err %v, want encode error", err)
+	}
+}
+
+func TestSetMaxConnLifetime(t *testing.T) {
+	var auths atomic.Int32
+
+	server := rcontest.NewServer(
+		rcontest.SetAuthHandler(func(c *rcontest.Context) {
+			auths.Add(1)
+			rcontest.AuthHandler(c)
+		}),
+		rcontest.SetCommandHandler(commandHandler),
+	)
+	defer server.Close()
+
+	t.Run("expired", func(t *testing.T) {
+		conn, err := rcon.Dial(server.Addr(), "", rcon.SetMaxConnLifetime(20*time.Millisecond))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		if _, err := conn.Execute("help"); err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		time.Sleep(30 * time.Millisecond)
+
+		if _, err := conn.Execute("help"); !errors.Is(err, rcon.ErrConnExpired) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrConnExpired)
+		}
+	})
+
+	t.Run("redialed", func(t *testing.T) {
+		conn, err := rcon.Dial(server.Addr(), "",
+			rcon.SetMaxConnLifetime(20*time.Millisecond), rcon.SetAutoReconnect(1, time.Millisecond))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		auths.Store(0)
+		time.Sleep(30 * time.Millisecond)
+
+		result, err := conn.Execute("help")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if result != "lorem ipsum dolor sit amet" {
+			t.Errorf("got result %q, want %q", result, "lorem ipsum dolor sit amet")
+		}
+
+		if n := auths.Load(); n != 1 {
+			t.Errorf("got %d auths, want %d", n, 1)
+		}
+	})
+}
+
+func TestConn_LastPacket(t *testing.T) {
+	server := rcontest.NewServer(rcontest.SetCommandHandler(commandHandler))
+	defer server.Close()
+
+	conn, err := rcon.Dial(server.Addr(), "")
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Execute("help"); err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	packet := conn.LastPacket()
+	if packet == nil {
+		t.Fatalf("got packet %v, want response packet", packet)
+	}
+
+	if packet.Type != rcon.SERVERDATA_RESPONSE_VALUE || packet.Body() != "lorem ipsum dolor sit amet" {
+		t.Errorf("got packet type %d body %q, want help response", packet.Type, packet.Body())
+	}
+
+	if packet.Size != int32(len(packet.Body()))+rcon.MinPacketSize {
+		t.Errorf("got size %d, want %d", packet.Size, int32(len(packet.Body()))+rcon.MinPacketSize)
+	}
+
+	packet.ID = -100
+
+	if conn.LastPacket().ID == packet.ID {
+		t.Error("got modified packet, want copy")
+	}
+
+	if _, err := conn.Execute(""); !errors.Is(err, rcon.ErrCommandEmpty) {
+		t.Fatalf("got err %q, want %q", err, rcon.ErrCommandEmpty)
+	}
+
+	if packet := conn.LastPacket(); packet != nil {
+		t.Errorf("got packet %+v, want nil", packet)
+	}
+}
+
+func TestDialAndExecute(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		server := rcontest.NewServer(
+			rcontest.SetSettings(rcontest.Settings{Password: "password"}),
+			rcontest.SetCommandHandler(commandHandler),
+		)
+		defer server.Close()
+
+		result, err := rcon.DialAndExecute(server.Addr(), "password", "help")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if result != "lorem ipsum dolor sit amet" {
+			t.Errorf("got result %q, want %q", result, "lorem ipsum dolor sit amet")
+		}
+	})
+
+	t.Run("auth failed", func(t *testing.T) {
+		server := rcontest.NewServer(
+			rcontest.SetSettings(rcontest.Settings{Password: "password"}),
+			rcontest.SetCommandHandler(commandHandler),
+		)
+		defer server.Close()
+
+		_, err := rcon.DialAndExecute(server.Addr(), "wrong", "help")
+		if !errors.Is(err, rcon.ErrAuthFailed) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrAuthFailed)
+		}
+	})
+
+	t.Run("command discarded before auth", func(t *testing.T) {
+		var discarded atomic.Bool
+
+		server := rcontest.NewServer(rcontest.SetCommandHandler(func(c *rcontest.Context) {
+			if discarded.CompareAndSwap(false, true) {
+				return
+			}
+
+			commandHandler(c)
+		}))
+		defer server.Close()
+
+		_, err := rcon.DialAndExecute(server.Addr(), "", "help", rcon.SetDeadline(100*time.Millisecond))
+		if !errors.Is(err, os.ErrDeadlineExceeded) {
+			t.Fatalf("got err %q, want %q", err, os.ErrDeadlineExceeded)
+		}
+
+		discarded.Store(false)
+
+		result, err := rcon.DialAndExecute(server.Addr(), "", "help",
+			rcon.SetDeadline(100*time.Millisecond), rcon.SetResendDiscardedCommand(true))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if result != "lorem ipsum dolor sit amet" {
+			t.Errorf("got result %q, want %q", result, "lorem ipsum dolor sit amet")
+		}
+	})
+
+	t.Run("empty command", func(t *testing.T) {
+		if _, err := rcon.DialAndExecute("127.0.0.2:12345", "", ""); !errors.Is(err, rcon.ErrCommandEmpty) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrCommandEmpty)
+		}
+	})
+}
+
+func TestSetPaddingMode(t *testing.T) {
+	server := rcontest.NewServer(rcontest.SetCommandHandler(commandHandler))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		mode    rcon.PaddingMode
+		want    string
+		wantErr error
+	}{
+		{name: "strict", mode: rcon.PaddingStrict, wantErr: rcon.ErrInvalidPacketPadding},
+		{name: "lenient", mode: rcon.PaddingLenient, want: ""},
+		{name: "none", mode: rcon.PaddingNone, want: "\x00\x01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, err := rcon.Dial(server.Addr(), "", rcon.SetPaddingMode(tt.mode))
+			if err != nil {
+				t.Fatalf("got err %q, want %v", err, nil)
+			}
+			defer conn.Close()
+
+			result, err := conn.Execute("padding")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got err %q, want %v", err, tt.wantErr)
+			}
+
+			if err == nil && result != tt.want {
+				t.Errorf("got result %q, want %q", result, tt.want)
+			}
+		})
+	}
+}
+
+func TestConn_Cancel(t *testing.T) {
+	started := make(chan struct{})
+
+	server := rcontest.NewServer(rcontest.SetCommandHandler(func(c *rcontest.Context) {
+		if c.Request().Body() == "hang" {
+			close(started)
+
+			return
+		}
+
+		commandHandler(c)
+	}))
+	defer server.Close()
+
+	conn, err := rcon.Dial(server.Addr(), "")
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	// Cancel without in-flight command does nothing.
+	conn.Cancel()
+
+	if _, err := conn.Execute("help"); err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	go func() {
+		<-started
+		conn.Cancel()
+	}()
+
+	start := time.Now()
+
+	if _, err := conn.Execute("hang"); !errors.Is(err, rcon.ErrCanceled) {
+		t.Fatalf("got err %q, want %q", err, rcon.ErrCanceled)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("got canceled after %s, want immediately", elapsed)
+	}
+
+	result, err := conn.Execute("help")
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	if result != "lorem ipsum dolor sit amet" {
+		t.Errorf("got result %q, want %q", result, "lorem ipsum dolor sit amet")
+	}
+}
+
+func TestSetResponseTerminator(t *testing.T) {
+	var mirrored atomic.Bool
+
+	server := rcontest.NewServer(
+		rcontest.SetCommandHandler(func(c *rcontest.Context) {
+			for _, fragment := range []string{"lorem ", "ipsum<E", "ND>"} {
+				rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, fragment).WriteTo(c.Conn())
+			}
+		}),
+		rcontest.SetMirrorHandler(func(c *rcontest.Context) {
+			mirrored.Store(true)
+			rcontest.MirrorHandler(c)
+		}),
+	)
+	defer server.Close()
+
+	conn, err := rcon.Dial(server.Addr(), "", rcon.SetMultiPacket(true), rcon.SetResponseTerminator("<END>"))
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	t.Run("split terminator", func(t *testing.T) {
+		result, err := conn.Execute("multi")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if result != "lorem ipsum" {
+			t.Errorf("got result %q, want %q", result, "lorem ipsum")
+		}
+
+		if mirrored.Load() {
+			t.Error("got sentinel sent, want not sent")
+		}
+	})
+
+	t.Run("next command", func(t *testing.T) {
+		result, err := conn.Execute("multi")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if result != "lorem ipsum" {
+			t.Errorf("got result %q, want %q", result, "lorem ipsum")
+		}
+	})
+}
+
+func TestSetSlowCommandThreshold(t *testing.T) {
+	server := rcontest.NewServer(rcontest.SetCommandHandler(func(c *rcontest.Context) {
+		if c.Request().Body() == "slow" {
+			time.Sleep(50 * time.Millisecond)
+		}
+
+		rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, "ok").WriteTo(c.Conn())
+	}))
+	defer server.Close()
+
+	var slow []string
+
+	conn, err := rcon.Dial(server.Addr(), "", rcon.SetSlowCommandThreshold(25*time.Millisecond, func(command string, took time.Duration) {
+		if took <= 25*time.Millisecond {
+			t.Errorf("got took %s, want more than %s", took, 25*time.Millisecond)
+		}
+
+		slow = append(slow, command)
+	}))
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	for _, command := range []string{"fast", "slow", "fast"} {
+		if _, err := conn.Execute(command); err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+	}
+
+	if len(slow) != 1 || slow[0] != "slow" {
+		t.Errorf("got slow commands %q, want %q", slow, []string{"slow"})
+	}
+}
+
+func TestSetCommandPipeline(t *testing.T) {
+	server := rcontest.NewServer(rcontest.SetCommandHandler(func(c *rcontest.Context) {
+		rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, c.Request().Body()).WriteTo(c.Conn())
+	}))
+	defer server.Close()
+
+	errForbidden := errors.New("forbidden")
+
+	conn, err := rcon.Dial(server.Addr(), "", rcon.SetCommandPipeline(
+		func(command string) (string, error) {
+			if command == "stop" {
+				return command, errForbidden
+			}
+
+			return command, nil
+		},
+		rcon.PrefixCommand("/"),
+		rcon.AppendNewline(),
+	))
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	t.Run("transformed", func(t *testing.T) {
+		for _, command := range []string{"list", "/list"} {
+			result, err := conn.Execute(command)
+			if err != nil {
+				t.Fatalf("got err %q, want %v", err, nil)
+			}
+
+			if result != "/list\n" {
+				t.Errorf("got result %q, want %q", result, "/list\n")
+			}
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		if _, err := conn.Execute("stop"); !errors.Is(err, errForbidden) {
+			t.Errorf("got err %q, want %q", err, errForbidden)
+		}
+	})
+
+	t.Run("called once", func(t *testing.T) {
+		calls := 0
+
+		conn, err := rcon.Dial(server.Addr(), "", rcon.SetStripCommandEcho(true), rcon.SetCommandPipeline(
+			func(command string) (string, error) {
+				calls++
+
+				return command, nil
+			},
+		))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		if _, err := conn.Execute("list"); err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if _, err := conn.ExecutePipeline([]string{"list", "status"}); err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if calls != 3 {
+			t.Errorf("got %d calls, want %d", calls, 3)
+		}
+	})
+}
+
+func TestSetResponsePipeline(t *testing.T) {
+	server := rcontest.NewServer(rcontest.SetCommandHandler(func(c *rcontest.Context) {
+		rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, "[Server] "+c.Request().Body()+" \n").WriteTo(c.Conn())
+	}))
+	defer server.Close()
+
+	errBroken := errors.New("broken")
+
+	conn, err := rcon.Dial(server.Addr(), "", rcon.SetResponsePipeline(
+		rcon.StripPrefix("[Server] "),
+		rcon.TrimResponse(),
+		func(response string) (string, error) {
+			if response == "broken" {
+				return response, errBroken
+			}
+
+			return strings.ToUpper(response), nil
+		},
+	))
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	t.Run("transformed", func(t *testing.T) {
+		result, err := conn.Execute("list")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if result != "LIST" {
+			t.Errorf("got result %q, want %q", result, "LIST")
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		if _, err := conn.Execute("broken"); !errors.Is(err, errBroken) {
+			t.Errorf("got err %q, want %q", err, errBroken)
+		}
+	})
+
+	t.Run("bytes", func(t *testing.T) {
+		result, err := conn.ExecuteBytes("list")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if string(result) != "[Server] list \n" {
+			t.Errorf("got result %q, want %q", result, "[Server] list \n")
+		}
+	})
+}
+
+func TestSetRecoverCallbacks(t *testing.T) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	server := rcontest.NewServer(rcontest.SetCommandHandler(commandHandler))
+	defer server.Close()
+
+	var panicked atomic.Bool
+
+	conn, err := rcon.Dial(server.Addr(), "",
+		rcon.SetConnStateFunc(func(from rcon.ConnState, to rcon.ConnState) {
+			panic("state")
+		}),
+		rcon.SetReadInterceptor(func(p rcon.Packet) (rcon.Packet, error) {
+			if p.Body() != "" && !panicked.Swap(true) {
+				panic("interceptor")
+			}
+
+			return p, nil
+		}),
+	)
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Execute("help"); !errors.Is(err, rcon.ErrCallbackPanic) {
+		t.Errorf("got err %q, want %q", err, rcon.ErrCallbackPanic)
+	}
+
+	result, err := conn.Execute("help")
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	if result != "lorem ipsum dolor sit amet" {
+		t.Errorf("got result %q, want %q", result, "lorem ipsum dolor sit amet")
+	}
+}
+
+func TestSetStripColorCodes(t *testing.T) {
+	server := rcontest.NewServer(rcontest.SetCommandHandler(func(c *rcontest.Context) {
+		rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, "§6There are §c1§6 players online:§r Steve").WriteTo(c.Conn())
+	}))
+	defer server.Close()
+
+	conn, err := rcon.Dial(server.Addr(), "", rcon.SetStripColorCodes(true))
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	result, err := conn.Execute("list")
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	if result != "There are 1 players online: Steve" {
+		t.Errorf("got result %q, want %q", result, "There are 1 players online: Steve")
+	}
+}
+
+func TestSetMinPacketSize(t *testing.T) {
+	var size atomic.Int32
+
+	server := rcontest.NewServer(rcontest.SetCommandHandler(func(c *rcontest.Context) {
+		size.Store(c.Request().Size)
+
+		// The server reads the command up to the first null.
+		command, _, _ := strings.Cut(c.Request().Body(), "\x00")
+
+		rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, command).WriteTo(c.Conn())
+	}))
+	defer server.Close()
+
+	conn, err := rcon.Dial(server.Addr(), "", rcon.SetMinPacketSize(64))
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	for _, command := range []string{"help", strings.Repeat("a", 100)} {
+		result, err := conn.Execute(command)
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if result != command {
+			t.Errorf("got result %q, want %q", result, command)
+		}
+
+		if want := max(int32(64), int32(len(command))+rcon.MinPacketSize); size.Load() != want {
+			t.Errorf("got size %d, want %d", size.Load(), want)
+		}
+	}
+}
+
+func TestSetResponsePrefixSkip(t *testing.T) {
+	writeWithPrefix := func(c *rcontest.Context, packet *rcon.Packet) {
+		c.Conn().Write([]byte("MAGI"))
+		packet.WriteTo(c.Conn())
+	}
+
+	server := rcontest.NewServer(
+		rcontest.SetAuthHandler(func(c *rcontest.Context) {
+			writeWithPrefix(c, rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, ""))
+			writeWithPrefix(c, rcon.NewPacket(rcon.SERVERDATA_AUTH_RESPONSE, c.Request().ID, ""))
+		}),
+		rcontest.SetCommandHandler(func(c *rcontest.Context) {
+			writeWithPrefix(c, rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, "ok"))
+		}),
+	)
+	defer server.Close()
+
+	conn, err := rcon.Dial(server.Addr(), "", rcon.SetResponsePrefixSkip(4))
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	for i := 0; i < 2; i++ {
+		result, err := conn.Execute("status")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if result != "ok" {
+			t.Errorf("got result %q, want %q", result, "ok")
+		}
+	}
+}
+
+func TestSetAdaptiveTimeout(t *testing.T) {
+	server := rcontest.NewServer(rcontest.SetCommandHandler(func(c *rcontest.Context) {
+		if c.Request().Body() == "hung" {
+			time.Sleep(time.Second)
+		}
+
+		rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, "ok").WriteTo(c.Conn())
+	}))
+	defer server.Close()
+
+	conn, err := rcon.Dial(server.Addr(), "", rcon.SetDeadline(3*time.Second), rcon.SetAdaptiveTimeout(true))
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	if timeout := conn.CurrentTimeout(); timeout != 3*time.Second {
+		t.Errorf("got timeout %s, want %s", timeout, 3*time.Second)
+	}
+
+	for i := 0; i < 5; i++ {
+		if _, err := conn.Execute("status"); err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+	}
+
+	if timeout := conn.CurrentTimeout(); timeout != rcon.MinAdaptiveTimeout {
+		t.Errorf("got timeout %s, want %s", timeout, rcon.MinAdaptiveTimeout)
+	}
+
+	start := time.Now()
+
+	if _, err := conn.Execute("hung"); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Errorf("got err %q, want %q", err, os.ErrDeadlineExceeded)
+	}
+
+	if took := time.Since(start); took >= time.Second {
+		t.Errorf("got took %s, want less than %s", took, time.Second)
+	}
+}
+
+func TestDialUnix(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "rcon.sock")
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Skipf("unix sockets are not supported: %s", err)
+	}
+
+	server := rcontest.NewUnstartedServer(
+		rcontest.SetSettings(rcontest.Settings{Password: "password"}),
+		rcontest.SetCommandHandler(commandHandler),
+	)
+	server.Listener.Close()
+	server.Listener = listener
+	server.Start()
+	defer server.Close()
+
+	conn, err := rcon.DialUnix(socketPath, "password")
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	result, err := conn.Execute("help")
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	if result != "lorem ipsum dolor sit amet" {
+		t.Errorf("got result %q, want %q", result, "lorem ipsum dolor sit amet")
+	}
+}
+
+func TestConn_ExecuteChecked(t *testing.T) {
+	server := rcontest.NewServer(rcontest.SetCommandHandler(func(c *rcontest.Context) {
+		var response string
+
+		switch c.Request().Body() {
+		case "kick Steve":
+			response = "Kicked Steve"
+		case "kick Nobody":
+			response = "Denied: no such player"
+		case "kick Alex":
+			response = "Error: no such player"
+		case "stats":
+			response = "errors: 0"
+		default:
+			response = "Unknown command \"" + c.Request().Body() + "\""
+		}
+
+		rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, response).WriteTo(c.Conn())
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		options  []rcon.Option
+		command  string
+		wantErr  error
+		response string
+	}{
+		{name: "unmatched", command: "kick Steve", response: "Kicked Steve"},
+		{name: "default pattern", command: "kickk", wantErr: rcon.ErrCommandRejected, response: "Unknown command \"kickk\""},
+		{name: "default error pattern", command: "kick Alex", wantErr: rcon.ErrCommandRejected, response: "Error: no such player"},
+		{name: "error word prefix", command: "stats", response: "errors: 0"},
+		{name: "custom pattern", options: []rcon.Option{rcon.SetErrorPatterns([]string{"denied:"})}, command: "kick Nobody", wantErr: rcon.ErrCommandRejected, response: "Denied: no such player"},
+		{name: "disabled", options: []rcon.Option{rcon.SetErrorPatterns(nil)}, command: "kickk", response: "Unknown command \"kickk\""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, err := rcon.Dial(server.Addr(), "", tt.options...)
+			if err != nil {
+				t.Fatalf("got err %q, want %v", err, nil)
+			}
+			defer conn.Close()
+
+			response, err := conn.ExecuteChecked(tt.command)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("got err %q, want %v", err, tt.wantErr)
+			}
+
+			if err != nil && !strings.Contains(err.Error(), tt.response) {
+				t.Errorf("got err %q, want to contain %q", err, tt.response)
+			}
+
+			if response != tt.response {
+				t.Errorf("got response %q, want %q", response, tt.response)
+			}
+		})
+	}
+}
+
+func TestConn_ExecuteTimeout(t *testing.T) {
+	server := rcontest.NewServer(
+		rcontest.SetSettings(rcontest.Settings{CommandResponseDelay: 150 * time.Millisecond}),
+		rcontest.SetCommandHandler(commandHandler),
+	)
+	defer server.Close()
+
+	tests := []struct {
+		name         string
+		deadline     time.Duration
+		connDeadline time.Duration
+		timeout      time.Duration
+		wantErr      error
+	}{
+		{name: "extends deadline", deadline: 50 * time.Millisecond, timeout: time.Second},
+		{name: "shortens deadline", deadline: time.Second, timeout: 50 * time.Millisecond, wantErr: os.ErrDeadlineExceeded},
+		{name: "extends conn deadline", deadline: time.Second, connDeadline: 50 * time.Millisecond, timeout: time.Second},
+		{
+			name: "shortens conn deadline", deadline: time.Second, connDeadline: time.Second, timeout: 50 * time.Millisecond,
+			wantErr: os.ErrDeadlineExceeded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, err := rcon.Dial(server.Addr(), "", rcon.SetDeadline(tt.deadline))
+			if err != nil {
+				t.Fatalf("got err %q, want %v", err, nil)
+			}
+			defer conn.Close()
+
+			if tt.connDeadline != 0 {
+				if err := conn.SetDeadline(time.Now().Add(tt.connDeadline)); err != nil {
+					t.Fatalf("got err %q, want %v", err, nil)
+				}
+			}
+
+			if _, err := conn.ExecuteTimeout("help", tt.timeout); !errors.Is(err, tt.wantErr) {
+				t.Errorf("got err %q, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSetAliases(t *testing.T) {
+	server := rcontest.NewServer(rcontest.SetCommandHandler(func(c *rcontest.Context) {
+		rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, c.Request().Body()).WriteTo(c.Conn())
+	}))
+	defer server.Close()
+
+	conn, err := rcon.Dial(server.Addr(), "", rcon.SetAliases(map[string]string{
+		"wl":     "whitelist",
+		"wl add": `addusertowhitelist "$1"`,
+		"say":    "broadcast [$$] $*",
+		"tp":     "teleport $2 $1",
+	}), rcon.SetCommandPipeline(rcon.PrefixCommand("/")))
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	tests := []struct {
+		command string
+		want    string
+	}{
+		{command: "wl add Steve", want: `/addusertowhitelist "Steve"`},
+		{command: "wl list", want: "/whitelist list"},
+		{command: "say hello  world", want: "/broadcast [$] hello world"},
+		{command: "tp Steve Alex", want: "/teleport Alex Steve"},
+		{command: "list", want: "/list"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			result, err := conn.Execute(tt.command)
+			if err != nil {
+				t.Fatalf("got err %q, want %v", err, nil)
+			}
+
+			if result != tt.want {
+				t.Errorf("got result %q, want %q", result, tt.want)
+			}
+		})
+	}
+
+	t.Run("missing argument", func(t *testing.T) {
+		if _, err := conn.Execute("tp Steve"); !errors.Is(err, rcon.ErrAliasArgument) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrAliasArgument)
+		}
+	})
+}
+
+func TestSetStripCommandEcho(t *testing.T) {
+	tests := []struct {
+		name     string
+		response func(command string) string
+		want     string
+	}{
+		{name: "echo", response: func(command string) string { return command + "\nplayers: 0" }, want: "players: 0"},
+		{name: "echo with prompt", response: func(command string) string { return "> " + command + "\r\nplayers: 0" }, want: "players: 0"},
+		{name: "echo only", response: func(command string) string { return command }, want: ""},
+		{name: "no echo", response: func(command string) string { return "players: 0\n" + command }, want: "players: 0\nlist"},
+		{name: "command in first line", response: func(string) string { return "listing players\n" }, want: "listing players\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := rcontest.NewServer(rcontest.SetCommandHandler(func(c *rcontest.Context) {
+				rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, tt.response(c.Request().Body())).WriteTo(c.Conn())
+			}))
+			defer server.Close()
+
+			conn, err := rcon.Dial(server.Addr(), "", rcon.SetStripCommandEcho(true))
+			if err != nil {
+				t.Fatalf("got err %q, want %v", err, nil)
+			}
+			defer conn.Close()
+
+			result, err := conn.Execute("list")
+			if err != nil {
+				t.Fatalf("got err %q, want %v", err, nil)
+			}
+
+			if result != tt.want {
+				t.Errorf("got result %q, want %q", result, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetResponseValidator(t *testing.T) {
+	var sent atomic.Int32
+
+	server := rcontest.NewServer(rcontest.SetCommandHandler(func(c *rcontest.Context) {
+		// Every second response is corrupt.
+		body := "players: 3"
+		if sent.Add(1)%2 == 1 {
+			body = "pl\x7fyers: 3"
+		}
+
+		rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, body).WriteTo(c.Conn())
+	}))
+	defer server.Close()
+
+	errGarbage := errors.New("garbage")
+
+	validator := func(command string, response string) error {
+		if strings.ContainsRune(response, 0x7f) {
+			return errGarbage
+		}
+
+		return nil
+	}
+
+	t.Run("corrupt", func(t *testing.T) {
+		sent.Store(0)
+
+		conn, err := rcon.Dial(server.Addr(), "", rcon.SetResponseValidator(validator))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		_, err = conn.Execute("players")
+		if !errors.Is(err, rcon.ErrResponseCorrupt) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrResponseCorrupt)
+		}
+
+		if !errors.Is(err, errGarbage) {
+			t.Errorf("got err %q, want %q", err, errGarbage)
+		}
+	})
+
+	t.Run("retry", func(t *testing.T) {
+		sent.Store(0)
+
+		conn, err := rcon.Dial(server.Addr(), "", rcon.SetResponseValidator(validator), rcon.SetResponseValidatorRetries(1, 0))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		result, err := conn.Execute("players")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if result != "players: 3" {
+			t.Errorf("got result %q, want %q", result, "players: 3")
+		}
+
+		if got := sent.Load(); got != 2 {
+			t.Errorf("got %d commands sent, want %d", got, 2)
+		}
+	})
+
+	t.Run("auto reconnect", func(t *testing.T) {
+		sent.Store(0)
+
+		conn, err := rcon.Dial(server.Addr(), "", rcon.SetResponseValidator(validator), rcon.SetAutoReconnect(1, 0))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		if _, err := conn.Execute("players"); !errors.Is(err, rcon.ErrResponseCorrupt) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrResponseCorrupt)
+		}
+
+		if got := sent.Load(); got != 1 {
+			t.Errorf("got %d commands sent, want %d", got, 1)
+		}
+	})
+}
+
+func TestSetExpectTrailingStatus(t *testing.T) {
+	const statusType = 5
+
+	server := rcontest.NewServer(rcontest.SetCommandHandler(func(c *rcontest.Context) {
+		rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, c.Request().Body()).WriteTo(c.Conn())
+		rcon.NewPacket(statusType, c.Request().ID, "OK").WriteTo(c.Conn())
+	}))
+	defer server.Close()
+
+	conn, err := rcon.Dial(server.Addr(), "", rcon.SetExpectTrailingStatus(true))
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	for _, command := range []string{"status", "players"} {
+		result, err := conn.Execute(command)
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if result != command {
+			t.Errorf("got result %q, want %q", result, command)
+		}
+
+		if last := conn.LastPacket(); last.Type != statusType || last.Body() != "OK" {
+			t.Errorf("got last packet type %d body %q, want %d %q", last.Type, last.Body(), statusType, "OK")
+		}
+	}
+
+	t.Run("stream", func(t *testing.T) {
+		stream, err := conn.ExecuteStream("status")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer stream.Close()
+
+		if body, _ := io.ReadAll(stream); string(body) != "status" {
+			t.Errorf("got body %q, want %q", body, "status")
+		}
+	})
+
+	t.Run("func", func(t *testing.T) {
+		var bodies []string
+
+		if err := conn.ExecuteFunc("status", func(body string) error {
+			bodies = append(bodies, body)
+
+			return nil
+		}); err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if len(bodies) != 1 || bodies[0] != "status" {
+			t.Errorf("got bodies %q, want %q", bodies, []string{"status"})
+		}
+	})
+
+	t.Run("pipeline", func(t *testing.T) {
+		results, err := conn.ExecutePipeline([]string{"status", "players"})
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if fmt.Sprint(results) != fmt.Sprint([]string{"status", "players"}) {
+			t.Errorf("got results %q, want %q", results, []string{"status", "players"})
+		}
+	})
+
+	// The status packets of the previous commands are consumed, so the
+	// response matches the command.
+	if result, err := conn.Execute("players"); err != nil || result != "players" {
+		t.Errorf("got result %q, err %v, want %q", result, err, "players")
+	}
+}
+
+func TestSetTreatEmptyAsError(t *testing.T) {
+	server := rcontest.NewServer(rcontest.SetCommandHandler(func(c *rcontest.Context) {
+		response := ""
+		if c.Request().Body() == "status" {
+			response = "players: 0"
+		}
+
+		rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, response).WriteTo(c.Conn())
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		enabled bool
+		wantErr error
+	}{
+		{name: "empty is success", enabled: false, wantErr: nil},
+		{name: "empty is error", enabled: true, wantErr: rcon.ErrEmptyResponse},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, err := rcon.Dial(server.Addr(), "", rcon.SetTreatEmptyAsError(tt.enabled))
+			if err != nil {
+				t.Fatalf("got err %q, want %v", err, nil)
+			}
+			defer conn.Close()
+
+			result, err := conn.Execute("say hello")
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("got err %v, want %v", err, tt.wantErr)
+			}
+
+			if result != "" {
+				t.Errorf("got result %q, want %q", result, "")
+			}
+
+			result, err = conn.Execute("status")
+			if err != nil || result != "players: 0" {
+				t.Errorf("got result %q err %v, want %q %v", result, err, "players: 0", nil)
+			}
+		})
+	}
 }
@@ -0,0 +1,7 @@
+module github.com/gorcon/rcon/rconcharset
+
+go 1.21
+
+require github.com/gorcon/rcon v0.0.0-20261014094545-a2e008d9d734
+
+require golang.org/x/text v0.14.0
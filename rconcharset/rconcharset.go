@@ -0,0 +1,31 @@
+// Package rconcharset transcodes RCON commands and responses to charsets of
+// localized servers, such as Windows-1251 or Shift JIS, using encodings of
+// golang.org/x/text. Pass the option of SetCharset to rcon.Dial.
+package rconcharset
+
+import (
+	"github.com/gorcon/rcon"
+	"golang.org/x/text/encoding"
+)
+
+// charset is rcon.Transcoder of encoding.
+type charset struct {
+	encoding encoding.Encoding
+}
+
+// SetCharset injects encoding of commands and responses of the server, for
+// example charmap.Windows1251 or japanese.ShiftJIS.
+func SetCharset(encoding encoding.Encoding) rcon.Option {
+	return rcon.SetTranscoder(charset{encoding: encoding})
+}
+
+// Encode converts command from UTF-8 to the charset. Characters which are not
+// in the charset return error.
+func (c charset) Encode(command string) (string, error) {
+	return c.encoding.NewEncoder().String(command)
+}
+
+// Decode converts response from the charset to UTF-8.
+func (c charset) Decode(response string) (string, error) {
+	return c.encoding.NewDecoder().String(response)
+}
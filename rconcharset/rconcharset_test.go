@@ -0,0 +1,76 @@
+package rconcharset_test
+
+import (
+	"testing"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon/rconcharset"
+	"github.com/gorcon/rcon/rcontest"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+)
+
+func TestSetCharset(t *testing.T) {
+	var received string
+
+	server := rcontest.NewServer(rcontest.SetCommandHandler(func(c *rcontest.Context) {
+		received = c.Request().Body()
+		rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, c.Request().Body()).WriteTo(c.Conn())
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		charset rcon.Option
+		command string
+		wire    string
+	}{
+		{
+			name:    "windows-1251",
+			charset: rconcharset.SetCharset(charmap.Windows1251),
+			command: "say Привет",
+			wire:    "say \xcf\xf0\xe8\xe2\xe5\xf2",
+		},
+		{
+			name:    "shift jis",
+			charset: rconcharset.SetCharset(japanese.ShiftJIS),
+			command: "say こんにちは",
+			wire:    "say \x82\xb1\x82\xf1\x82\xc9\x82\xbf\x82\xcd",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, err := rcon.Dial(server.Addr(), "", tt.charset)
+			if err != nil {
+				t.Fatalf("got err %q, want %v", err, nil)
+			}
+			defer conn.Close()
+
+			result, err := conn.Execute(tt.command)
+			if err != nil {
+				t.Fatalf("got err %q, want %v", err, nil)
+			}
+
+			if received != tt.wire {
+				t.Errorf("got command on the wire %q, want %q", received, tt.wire)
+			}
+
+			if result != tt.command {
+				t.Errorf("got result %q, want %q", result, tt.command)
+			}
+		})
+	}
+
+	t.Run("unsupported character", func(t *testing.T) {
+		conn, err := rcon.Dial(server.Addr(), "", rconcharset.SetCharset(charmap.Windows1251))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		if _, err := conn.Execute("say 日本"); err == nil {
+			t.Errorf("got err %v, want encode error", err)
+		}
+	})
+}
@@ -0,0 +1,79 @@
+// Package rconminecraft contains helpers for Minecraft servers, such as
+// handling of § formatting codes in responses.
+package rconminecraft
+
+import (
+	"strings"
+	"unicode"
+)
+
+// colorCodePrefix starts formatting codes of Minecraft text.
+const colorCodePrefix = '§'
+
+// ansiCodes maps Minecraft color and formatting codes to ANSI escape codes.
+var ansiCodes = map[rune]string{
+	'0': "30", '1': "34", '2': "32", '3': "36",
+	'4': "31", '5': "35", '6': "33", '7': "37",
+	'8': "90", '9': "94", 'a': "92", 'b': "96",
+	'c': "91", 'd': "95", 'e': "93", 'f': "97",
+	'k': "5", 'l': "1", 'm': "9", 'n': "4", 'o': "3", 'r': "0",
+}
+
+// StripColors removes § formatting codes from s, for example to show player
+// lists in logs.
+func StripColors(s string) string {
+	return convert(s, func(rune) string { return "" })
+}
+
+// ColorsToANSI replaces § formatting codes in s with ANSI escape codes for
+// terminals. Unknown codes are removed. If s contains any code, the result
+// ends with the reset code.
+func ColorsToANSI(s string) string {
+	colored := false
+
+	s = convert(s, func(code rune) string {
+		ansi, ok := ansiCodes[code]
+		if !ok {
+			return ""
+		}
+
+		colored = true
+
+		return "\x1b[" + ansi + "m"
+	})
+
+	if colored {
+		s += "\x1b[0m"
+	}
+
+	return s
+}
+
+// convert replaces each § code in s with the result of replace, which gets the
+// lowercase code character.
+func convert(s string, replace func(code rune) string) string {
+	if !strings.ContainsRune(s, colorCodePrefix) {
+		return s
+	}
+
+	var builder strings.Builder
+
+	builder.Grow(len(s))
+
+	code := false
+
+	for _, r := range s {
+		switch {
+		case code:
+			builder.WriteString(replace(unicode.ToLower(r)))
+
+			code = false
+		case r == colorCodePrefix:
+			code = true
+		default:
+			builder.WriteRune(r)
+		}
+	}
+
+	return builder.String()
+}
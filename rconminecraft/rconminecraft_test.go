@@ -0,0 +1,48 @@
+package rconminecraft_test
+
+import (
+	"testing"
+
+	"github.com/gorcon/rcon/rconminecraft"
+)
+
+func TestStripColors(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want string
+	}{
+		{name: "plain", s: "There are 0 of a max of 20 players online: ", want: "There are 0 of a max of 20 players online: "},
+		{name: "colors", s: "§6There are §c2§6 players online:§r Steve", want: "There are 2 players online: Steve"},
+		{name: "uppercase", s: "§LBold§R", want: "Bold"},
+		{name: "trailing prefix", s: "Steve§", want: "Steve"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rconminecraft.StripColors(tt.s); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestColorsToANSI(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want string
+	}{
+		{name: "plain", s: "Steve", want: "Steve"},
+		{name: "colors", s: "§cRed §lbold", want: "\x1b[91mRed \x1b[1mbold\x1b[0m"},
+		{name: "unknown code", s: "§zSteve", want: "Steve"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rconminecraft.ColorsToANSI(tt.s); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
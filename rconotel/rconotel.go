@@ -0,0 +1,82 @@
+// Package rconotel provides OpenTelemetry tracing of RCON connections and
+// commands. Tracer wraps rcon.DialContext and Conn.ExecuteContext in spans
+// which are children of the span in the passed context.
+package rconotel
+
+import (
+	"context"
+
+	"github.com/gorcon/rcon"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName is the name of the tracer.
+const instrumentationName = "github.com/gorcon/rcon/rconotel"
+
+// Span attributes.
+const (
+	AttributeCommand      = attribute.Key("rcon.command")
+	AttributeResponseSize = attribute.Key("rcon.response.size")
+	AttributeAddress      = attribute.Key("server.address")
+)
+
+// Tracer creates spans of Dial and Execute which are children of the span
+// from the passed context.
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+// NewTracer creates Tracer with spans from provider. If provider is nil, the
+// global tracer provider is used.
+func NewTracer(provider trace.TracerProvider) *Tracer {
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+
+	return &Tracer{tracer: provider.Tracer(instrumentationName)}
+}
+
+// DialContext connects to the server at address like rcon.DialContext within
+// the "rcon.Dial" span.
+func (t *Tracer) DialContext(ctx context.Context, address string, password string, options ...rcon.Option) (*rcon.Conn, error) {
+	ctx, span := t.tracer.Start(ctx, "rcon.Dial",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(AttributeAddress.String(address)),
+	)
+	defer span.End()
+
+	conn, err := rcon.DialContext(ctx, address, password, options...)
+	finish(span, err)
+
+	return conn, err
+}
+
+// ExecuteContext executes command on conn like conn.ExecuteContext within
+// the "rcon.Execute" span.
+func (t *Tracer) ExecuteContext(ctx context.Context, conn *rcon.Conn, command string) (string, error) {
+	ctx, span := t.tracer.Start(ctx, "rcon.Execute",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			AttributeCommand.String(command),
+			AttributeAddress.String(conn.RemoteAddr().String()),
+		),
+	)
+	defer span.End()
+
+	response, err := conn.ExecuteContext(ctx, command)
+	span.SetAttributes(AttributeResponseSize.Int(len(response)))
+	finish(span, err)
+
+	return response, err
+}
+
+// finish records err on span.
+func finish(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
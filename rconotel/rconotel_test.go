@@ -0,0 +1,77 @@
+package rconotel_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon/rconotel"
+	"github.com/gorcon/rcon/rcontest"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracer(t *testing.T) {
+	server := rcontest.NewServer(rcontest.SetCommandHandler(func(c *rcontest.Context) {
+		rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, "players: 0").WriteTo(c.Conn())
+	}))
+	defer server.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tracer := rconotel.NewTracer(provider)
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "parent")
+
+	conn, err := tracer.DialContext(ctx, server.Addr(), "")
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	if _, err := tracer.ExecuteContext(ctx, conn, "players"); err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	if _, err := tracer.ExecuteContext(ctx, conn, ""); !errors.Is(err, rcon.ErrCommandEmpty) {
+		t.Fatalf("got err %q, want %q", err, rcon.ErrCommandEmpty)
+	}
+
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 4 {
+		t.Fatalf("got %d spans, want %d", len(spans), 4)
+	}
+
+	for i, name := range []string{"rcon.Dial", "rcon.Execute", "rcon.Execute"} {
+		if spans[i].Name() != name {
+			t.Errorf("got span name %q, want %q", spans[i].Name(), name)
+		}
+
+		if spans[i].Parent().SpanID() != parent.SpanContext().SpanID() {
+			t.Errorf("got span %q without parent", spans[i].Name())
+		}
+	}
+
+	attributes := attribute.NewSet(spans[1].Attributes()...)
+
+	if got, _ := attributes.Value(rconotel.AttributeCommand); got.AsString() != "players" {
+		t.Errorf("got command %q, want %q", got.AsString(), "players")
+	}
+
+	if got, _ := attributes.Value(rconotel.AttributeAddress); got.AsString() != server.Addr() {
+		t.Errorf("got address %q, want %q", got.AsString(), server.Addr())
+	}
+
+	if got, _ := attributes.Value(rconotel.AttributeResponseSize); got.AsInt64() != int64(len("players: 0")) {
+		t.Errorf("got response size %d, want %d", got.AsInt64(), len("players: 0"))
+	}
+
+	if status := spans[2].Status(); status.Code != codes.Error {
+		t.Errorf("got status %v, want %v", status.Code, codes.Error)
+	}
+}
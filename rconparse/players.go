@@ -0,0 +1,159 @@
+// Package rconparse contains parsers of semi-structured responses of game
+// server commands.
+package rconparse
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidLine is returned when a line of the response cannot be parsed.
+var ErrInvalidLine = errors.New("invalid line")
+
+// Player is a player from the player list of a game server. Fields which the
+// game doesn't report are left empty.
+type Player struct {
+	Name    string
+	ID      string
+	Ping    int
+	Address string
+}
+
+// PlayerFormat parses the player list response of a specific game. Custom
+// formats can be used with ParsePlayers for games which are not supported.
+type PlayerFormat func(response string) ([]Player, error)
+
+// Known player list formats.
+var (
+	// Minecraft parses response of the "list" or "list uuids" command.
+	Minecraft PlayerFormat = parseMinecraft
+
+	// Rust parses players table from response of the "status" command.
+	Rust PlayerFormat = parseRust
+
+	// ProjectZomboid parses response of the "players" command.
+	ProjectZomboid PlayerFormat = parseProjectZomboid
+)
+
+var (
+	minecraftUUIDPlayer = regexp.MustCompile(`^(\S+) \(([0-9a-fA-F-]{36})\)$`)
+	rustPlayer          = regexp.MustCompile(`^(\d+)\s+"(.*)"\s+(\d+)\s+\S+\s+(\S+)`)
+)
+
+// ParsePlayers parses player list response using format. For lines which
+// cannot be parsed it returns the players parsed from other lines and an error
+// wrapping ErrInvalidLine for each such line.
+func ParsePlayers(response string, format PlayerFormat) ([]Player, error) {
+	return format(response)
+}
+
+// parseMinecraft parses "There are 2 of a max of 20 players online: Steve, Alex"
+// and the older "There are 2/20 players online:\nSteve, Alex" responses.
+func parseMinecraft(response string) ([]Player, error) {
+	_, list, ok := strings.Cut(response, ":")
+	if !ok {
+		return nil, invalidLine(response)
+	}
+
+	list = strings.TrimSpace(list)
+	if list == "" {
+		return []Player{}, nil
+	}
+
+	players := make([]Player, 0)
+
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+
+		if match := minecraftUUIDPlayer.FindStringSubmatch(name); match != nil {
+			players = append(players, Player{Name: match[1], ID: match[2]})
+
+			continue
+		}
+
+		players = append(players, Player{Name: name})
+	}
+
+	return players, nil
+}
+
+// parseRust parses players table which follows "id name ping connected addr"
+// header line in the "status" response.
+func parseRust(response string) ([]Player, error) {
+	players := make([]Player, 0)
+
+	var errs []error
+
+	header := false
+
+	for _, line := range splitLines(response) {
+		if !header {
+			header = strings.HasPrefix(line, "id ")
+
+			continue
+		}
+
+		match := rustPlayer.FindStringSubmatch(line)
+		if match == nil {
+			errs = append(errs, invalidLine(line))
+
+			continue
+		}
+
+		ping, _ := strconv.Atoi(match[3])
+
+		players = append(players, Player{Name: match[2], ID: match[1], Ping: ping, Address: match[4]})
+	}
+
+	if !header {
+		return players, invalidLine(response)
+	}
+
+	return players, errors.Join(errs...)
+}
+
+// parseProjectZomboid parses "Players connected (2): \n-admin\n-player" response.
+func parseProjectZomboid(response string) ([]Player, error) {
+	lines := splitLines(response)
+	if len(lines) == 0 || !strings.HasPrefix(lines[0], "Players connected") {
+		return nil, invalidLine(response)
+	}
+
+	players := make([]Player, 0, len(lines)-1)
+
+	var errs []error
+
+	for _, line := range lines[1:] {
+		name, ok := strings.CutPrefix(line, "-")
+		if !ok {
+			errs = append(errs, invalidLine(line))
+
+			continue
+		}
+
+		players = append(players, Player{Name: name})
+	}
+
+	return players, errors.Join(errs...)
+}
+
+// splitLines splits s into trimmed non-empty lines.
+func splitLines(s string) []string {
+	lines := make([]string, 0)
+
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines
+}
+
+// invalidLine returns error wrapping ErrInvalidLine for line.
+func invalidLine(line string) error {
+	return fmt.Errorf("rconparse: %w: %q", ErrInvalidLine, line)
+}
@@ -0,0 +1,88 @@
+package rconparse_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/gorcon/rcon/rconparse"
+)
+
+func TestParsePlayers(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		format   rconparse.PlayerFormat
+		want     []rconparse.Player
+		wantErr  error
+	}{
+		{
+			name:     "minecraft",
+			response: "There are 2 of a max of 20 players online: Steve, Alex",
+			format:   rconparse.Minecraft,
+			want:     []rconparse.Player{{Name: "Steve"}, {Name: "Alex"}},
+		},
+		{
+			name:     "minecraft uuids",
+			response: "There are 1 of a max of 20 players online: Steve (8667ba71-b85a-4004-af54-457a9734eed7)",
+			format:   rconparse.Minecraft,
+			want:     []rconparse.Player{{Name: "Steve", ID: "8667ba71-b85a-4004-af54-457a9734eed7"}},
+		},
+		{
+			name:     "minecraft empty",
+			response: "There are 0 of a max of 20 players online: ",
+			format:   rconparse.Minecraft,
+			want:     []rconparse.Player{},
+		},
+		{
+			name:     "minecraft invalid",
+			response: "Unknown command",
+			format:   rconparse.Minecraft,
+			wantErr:  rconparse.ErrInvalidLine,
+		},
+		{
+			name: "rust",
+			response: "hostname: My Server\n" +
+				"version : 2177 secure (secure mode enabled, connected to Steam3)\n" +
+				"map     : Procedural Map\n" +
+				"players : 2 (500 max) (0 queued) (0 joining)\n\n" +
+				"id                name         ping connected addr                 owner violation kicks\n" +
+				"76561198000000001 \"Player One\" 25   123.4s    10.0.0.1:28015             0.0       0\n" +
+				"broken line\n" +
+				"76561198000000002 \"Two\"        100  5.2s      10.0.0.2:28015             0.0       0\n",
+			format: rconparse.Rust,
+			want: []rconparse.Player{
+				{Name: "Player One", ID: "76561198000000001", Ping: 25, Address: "10.0.0.1:28015"},
+				{Name: "Two", ID: "76561198000000002", Ping: 100, Address: "10.0.0.2:28015"},
+			},
+			wantErr: rconparse.ErrInvalidLine,
+		},
+		{
+			name:     "project zomboid",
+			response: "Players connected (2): \n-admin\n-player\n",
+			format:   rconparse.ProjectZomboid,
+			want:     []rconparse.Player{{Name: "admin"}, {Name: "player"}},
+		},
+		{
+			name:     "custom",
+			response: "Steve",
+			format: func(response string) ([]rconparse.Player, error) {
+				return []rconparse.Player{{Name: response}}, nil
+			},
+			want: []rconparse.Player{{Name: "Steve"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := rconparse.ParsePlayers(tt.response, tt.format)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("got err %q, want %q", err, tt.wantErr)
+			}
+
+			if tt.want != nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
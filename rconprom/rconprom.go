@@ -0,0 +1,124 @@
+// Package rconprom provides rcon.Observer which exports Prometheus metrics of
+// connections and commands, such as active connections, command counts and
+// latency histograms labeled by the server address.
+package rconprom
+
+import (
+	"strings"
+
+	"github.com/gorcon/rcon"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Observer is rcon.Observer which updates Prometheus metrics. Metrics are
+// labeled by the server address and commands also by the command category.
+// Register it with prometheus.Registerer to export them.
+type Observer struct {
+	// Category returns the category label of command. Default is the first
+	// word of the command in lower case.
+	Category func(command string) string
+
+	commands    *prometheus.CounterVec
+	errors      *prometheus.CounterVec
+	duration    *prometheus.HistogramVec
+	bytesSent   *prometheus.CounterVec
+	bytesRecv   *prometheus.CounterVec
+	connections *prometheus.GaugeVec
+}
+
+// NewObserver creates Observer with metrics in namespace, for example
+// "rcon_commands_total" for namespace "rcon".
+func NewObserver(namespace string) *Observer {
+	commandLabels := []string{"address", "category"}
+
+	return &Observer{
+		commands: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "commands_total",
+			Help:      "Number of executed commands.",
+		}, commandLabels),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "command_errors_total",
+			Help:      "Number of failed commands.",
+		}, commandLabels),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "command_duration_seconds",
+			Help:      "Duration of commands.",
+			Buckets:   prometheus.DefBuckets,
+		}, commandLabels),
+		bytesSent: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "command_bytes_sent_total",
+			Help:      "Number of bytes of sent commands.",
+		}, commandLabels),
+		bytesRecv: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "response_bytes_received_total",
+			Help:      "Number of bytes of received responses.",
+		}, commandLabels),
+		connections: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "connections_active",
+			Help:      "Number of open connections.",
+		}, []string{"address"}),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (o *Observer) Describe(ch chan<- *prometheus.Desc) {
+	for _, collector := range o.collectors() {
+		collector.Describe(ch)
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (o *Observer) Collect(ch chan<- prometheus.Metric) {
+	for _, collector := range o.collectors() {
+		collector.Collect(ch)
+	}
+}
+
+// OnConnect counts the connection as active if Dial succeeded.
+func (o *Observer) OnConnect(event rcon.ConnectEvent) {
+	if event.Err == nil {
+		o.connections.WithLabelValues(event.Address).Inc()
+	}
+}
+
+// OnCommand updates metrics of commands.
+func (o *Observer) OnCommand(event rcon.CommandEvent) {
+	labels := prometheus.Labels{"address": event.Address, "category": o.category(event.Command)}
+
+	o.commands.With(labels).Inc()
+	o.duration.With(labels).Observe(event.Duration.Seconds())
+	o.bytesSent.With(labels).Add(float64(len(event.Command)))
+	o.bytesRecv.With(labels).Add(float64(event.ResponseSize))
+
+	if event.Err != nil {
+		o.errors.With(labels).Inc()
+	}
+}
+
+// OnClose counts the connection as closed. Conn calls it only for connections
+// counted by OnConnect, so failed dials don't decrement the gauge.
+func (o *Observer) OnClose(event rcon.CloseEvent) {
+	o.connections.WithLabelValues(event.Address).Dec()
+}
+
+// collectors returns all metrics of the observer.
+func (o *Observer) collectors() []prometheus.Collector {
+	return []prometheus.Collector{o.commands, o.errors, o.duration, o.bytesSent, o.bytesRecv, o.connections}
+}
+
+// category returns the category label of command.
+func (o *Observer) category(command string) string {
+	if o.Category != nil {
+		return o.Category(command)
+	}
+
+	name, _, _ := strings.Cut(strings.TrimSpace(command), " ")
+
+	return strings.ToLower(name)
+}
@@ -0,0 +1,88 @@
+package rconprom_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon/rconprom"
+	"github.com/gorcon/rcon/rcontest"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestObserver(t *testing.T) {
+	server := rcontest.NewServer(rcontest.SetCommandHandler(func(c *rcontest.Context) {
+		rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, "ok").WriteTo(c.Conn())
+	}))
+	defer server.Close()
+
+	observer := rconprom.NewObserver("rcon")
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(observer)
+
+	conn, err := rcon.Dial(server.Addr(), "", rcon.SetObserver(observer))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, command := range []string{"kick Steve", "kick Alex", "status"} {
+		if _, err := conn.Execute(command); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := conn.Execute(""); err == nil {
+		t.Fatal("got err nil, want error")
+	}
+
+	address := server.Addr()
+
+	want := `
+# HELP rcon_commands_total Number of executed commands.
+# TYPE rcon_commands_total counter
+rcon_commands_total{address="` + address + `",category=""} 1
+rcon_commands_total{address="` + address + `",category="kick"} 2
+rcon_commands_total{address="` + address + `",category="status"} 1
+# HELP rcon_command_errors_total Number of failed commands.
+# TYPE rcon_command_errors_total counter
+rcon_command_errors_total{address="` + address + `",category=""} 1
+# HELP rcon_connections_active Number of open connections.
+# TYPE rcon_connections_active gauge
+rcon_connections_active{address="` + address + `"} 1
+`
+
+	names := []string{"rcon_commands_total", "rcon_command_errors_total", "rcon_connections_active"}
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(want), names...); err != nil {
+		t.Error(err)
+	}
+
+	conn.Close()
+
+	if count := testutil.CollectAndCount(registry, "rcon_command_duration_seconds"); count != 3 {
+		t.Errorf("got %d duration series, want %d", count, 3)
+	}
+}
+
+func TestObserver_authFailed(t *testing.T) {
+	server := rcontest.NewServer(rcontest.SetSettings(rcontest.Settings{Password: "password"}))
+	defer server.Close()
+
+	observer := rconprom.NewObserver("rcon")
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(observer)
+
+	if _, err := rcon.Dial(server.Addr(), "wrong", rcon.SetObserver(observer)); err == nil {
+		t.Fatal("got err nil, want error")
+	}
+
+	if _, err := rcon.DialAndExecute(server.Addr(), "wrong", "status", rcon.SetObserver(observer)); err == nil {
+		t.Fatal("got err nil, want error")
+	}
+
+	if count := testutil.CollectAndCount(registry, "rcon_connections_active"); count != 0 {
+		t.Errorf("got %d active connections series, want %d", count, 0)
+	}
+}
@@ -0,0 +1,201 @@
+// Package rconquery discovers RCON ports of game servers through their query
+// protocol. Discovery is game-specific, currently only Source engine servers
+// are supported via A2S_INFO query which is described in the documentation:
+// https://developer.valvesoftware.com/wiki/Server_queries.
+package rconquery
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+// DefaultTimeout provides default timeout to query request.
+const DefaultTimeout = 5 * time.Second
+
+// A2S_INFO packet definitions.
+const (
+	packetHeader      int32 = -1
+	a2sInfoRequest    byte  = 'T'
+	a2sInfoResponse   byte  = 'I'
+	a2sInfoChallenge  byte  = 'A'
+	a2sInfoPayload          = "Source Engine Query\x00"
+	theShipAppID      int16 = 2400
+	edfPort           byte  = 0x80
+	maxResponseLength       = 1400
+)
+
+var (
+	// ErrInvalidResponse is returned when the server response is not
+	// a well-formed A2S_INFO response.
+	ErrInvalidResponse = errors.New("invalid query response")
+
+	// ErrPortNotFound is returned when the server response does not contain
+	// the port and query address has no port to fall back to.
+	ErrPortNotFound = errors.New("rcon port not found")
+)
+
+// DiscoverRCONPort queries Source engine server info on queryAddr and returns
+// the RCON port. Source engine servers listen RCON on TCP on the same port as
+// the game port, so the game port from A2S_INFO Extra Data Field is returned.
+// When the server doesn't report the game port, the port of queryAddr
+// is returned.
+func DiscoverRCONPort(queryAddr string) (int, error) {
+	conn, err := net.DialTimeout("udp", queryAddr, DefaultTimeout)
+	if err != nil {
+		return 0, fmt.Errorf("rconquery: %w", err)
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(DefaultTimeout)); err != nil {
+		return 0, fmt.Errorf("rconquery: %w", err)
+	}
+
+	response, err := queryInfo(conn)
+	if err != nil {
+		return 0, err
+	}
+
+	port, ok, err := parseInfoPort(response)
+	if err != nil {
+		return 0, err
+	}
+
+	if ok {
+		return port, nil
+	}
+
+	_, portStr, err := net.SplitHostPort(queryAddr)
+	if err != nil {
+		return 0, fmt.Errorf("rconquery: %w: %s", ErrPortNotFound, err.Error())
+	}
+
+	if port, err = strconv.Atoi(portStr); err != nil {
+		return 0, fmt.Errorf("rconquery: %w: %s", ErrPortNotFound, err.Error())
+	}
+
+	return port, nil
+}
+
+// queryInfo sends A2S_INFO request and returns response payload without
+// packet header. It answers the challenge if the server requests it.
+func queryInfo(conn net.Conn) ([]byte, error) {
+	request := newInfoRequest(nil)
+
+	for i := 0; i < 2; i++ {
+		if _, err := conn.Write(request); err != nil {
+			return nil, fmt.Errorf("rconquery: %w", err)
+		}
+
+		buffer := make([]byte, maxResponseLength)
+
+		n, err := conn.Read(buffer)
+		if err != nil {
+			return nil, fmt.Errorf("rconquery: %w", err)
+		}
+
+		buffer = buffer[:n]
+
+		if len(buffer) < 5 || int32(binary.LittleEndian.Uint32(buffer)) != packetHeader {
+			return nil, ErrInvalidResponse
+		}
+
+		switch buffer[4] {
+		case a2sInfoResponse:
+			return buffer[5:], nil
+		case a2sInfoChallenge:
+			if len(buffer) < 9 {
+				return nil, ErrInvalidResponse
+			}
+
+			request = newInfoRequest(buffer[5:9])
+		default:
+			return nil, ErrInvalidResponse
+		}
+	}
+
+	return nil, ErrInvalidResponse
+}
+
+// newInfoRequest creates A2S_INFO request packet with optional challenge.
+func newInfoRequest(challenge []byte) []byte {
+	buffer := bytes.NewBuffer(make([]byte, 0, 5+len(a2sInfoPayload)+len(challenge)))
+
+	_ = binary.Write(buffer, binary.LittleEndian, packetHeader)
+	buffer.WriteByte(a2sInfoRequest)
+	buffer.WriteString(a2sInfoPayload)
+	buffer.Write(challenge)
+
+	return buffer.Bytes()
+}
+
+// parseInfoPort parses A2S_INFO response payload and returns game port from
+// Extra Data Field. The ok result is false if the server doesn't report it.
+func parseInfoPort(payload []byte) (port int, ok bool, err error) {
+	r := bytes.NewReader(payload)
+
+	// Protocol version.
+	if _, err := r.ReadByte(); err != nil {
+		return 0, false, ErrInvalidResponse
+	}
+
+	// Name, map, folder and game strings.
+	for i := 0; i < 4; i++ {
+		if err := skipString(r); err != nil {
+			return 0, false, err
+		}
+	}
+
+	var appID int16
+	if err := binary.Read(r, binary.LittleEndian, &appID); err != nil {
+		return 0, false, ErrInvalidResponse
+	}
+
+	// Players, max players, bots, server type, environment, visibility, VAC.
+	skip := 7
+	if appID == theShipAppID {
+		// The Ship mode, witnesses and duration.
+		skip += 3
+	}
+
+	if _, err := r.Seek(int64(skip), io.SeekCurrent); err != nil || r.Len() == 0 {
+		return 0, false, ErrInvalidResponse
+	}
+
+	// Version string.
+	if err := skipString(r); err != nil {
+		return 0, false, err
+	}
+
+	edf, err := r.ReadByte()
+	if err != nil || edf&edfPort == 0 {
+		// Extra Data Field is optional.
+		return 0, false, nil
+	}
+
+	var gamePort uint16
+	if err := binary.Read(r, binary.LittleEndian, &gamePort); err != nil {
+		return 0, false, ErrInvalidResponse
+	}
+
+	return int(gamePort), true, nil
+}
+
+// skipString reads null-terminated string from r.
+func skipString(r *bytes.Reader) error {
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return ErrInvalidResponse
+		}
+
+		if b == 0x00 {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package rconquery_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"net"
+	"strconv"
+	"testing"
+
+	"github.com/gorcon/rcon/rconquery"
+)
+
+// newInfoResponse creates A2S_INFO response with optional game port in EDF.
+func newInfoResponse(port uint16) []byte {
+	buffer := bytes.NewBuffer(nil)
+
+	binary.Write(buffer, binary.LittleEndian, int32(-1))
+	buffer.WriteByte('I')
+	buffer.WriteByte(17)
+	buffer.WriteString("Test server\x00de_dust2\x00csgo\x00Counter-Strike: Global Offensive\x00")
+	binary.Write(buffer, binary.LittleEndian, int16(730))
+	buffer.Write([]byte{5, 10, 0, 'd', 'l', 0, 1})
+	buffer.WriteString("1.38.0.0\x00")
+
+	if port != 0 {
+		buffer.WriteByte(0x80)
+		binary.Write(buffer, binary.LittleEndian, port)
+	}
+
+	return buffer.Bytes()
+}
+
+// startQueryServer starts UDP server which responses with challenge first
+// and then with response.
+func startQueryServer(t *testing.T, response []byte) string {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buffer := make([]byte, 1400)
+		challenge := []byte{0x01, 0x02, 0x03, 0x04}
+
+		for {
+			n, addr, err := conn.ReadFrom(buffer)
+			if err != nil {
+				return
+			}
+
+			if !bytes.HasSuffix(buffer[:n], challenge) {
+				conn.WriteTo(append([]byte{0xFF, 0xFF, 0xFF, 0xFF, 'A'}, challenge...), addr)
+				continue
+			}
+
+			conn.WriteTo(response, addr)
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
+
+func TestDiscoverRCONPort(t *testing.T) {
+	t.Run("port from edf", func(t *testing.T) {
+		addr := startQueryServer(t, newInfoResponse(27016))
+
+		port, err := rconquery.DiscoverRCONPort(addr)
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if port != 27016 {
+			t.Errorf("got port %d, want %d", port, 27016)
+		}
+	})
+
+	t.Run("port from query address", func(t *testing.T) {
+		addr := startQueryServer(t, newInfoResponse(0))
+
+		port, err := rconquery.DiscoverRCONPort(addr)
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		_, portStr, _ := net.SplitHostPort(addr)
+		if strconv.Itoa(port) != portStr {
+			t.Errorf("got port %d, want %s", port, portStr)
+		}
+	})
+
+	t.Run("invalid response", func(t *testing.T) {
+		addr := startQueryServer(t, []byte{0xFF, 0xFF, 0xFF, 0xFF, 'I', 17, 'n', 'a', 'm', 'e'})
+
+		_, err := rconquery.DiscoverRCONPort(addr)
+		if !errors.Is(err, rconquery.ErrInvalidResponse) {
+			t.Errorf("got err %q, want %q", err, rconquery.ErrInvalidResponse)
+		}
+	})
+}
@@ -0,0 +1,145 @@
+package rconrecord
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/gorcon/rcon"
+)
+
+// Player is a net.Conn which replays a cassette. When a request is written,
+// the first unused exchange with the same request type and body is found and
+// its responses are queued for reading. Response ids equal to the recorded
+// request id are replaced with the id of the actual request, so the client
+// may generate other ids than in the recorded session. Reading with no queued
+// responses returns io.EOF, because the server sent nothing more during the
+// recording.
+type Player struct {
+	exchanges []Exchange
+	used      []bool
+	mu        sync.Mutex
+	written   []byte
+	pending   bytes.Buffer
+	closed    bool
+}
+
+// NewPlayer returns a Player which replays the cassette read from r.
+func NewPlayer(r io.Reader) (*Player, error) {
+	exchanges, err := ReadCassette(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Player{exchanges: exchanges, used: make([]bool, len(exchanges))}, nil
+}
+
+// Read reads queued responses into b.
+func (p *Player) Read(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return 0, net.ErrClosed
+	}
+
+	return p.pending.Read(b)
+}
+
+// Write parses requests from b and queues recorded responses to them.
+// It returns ErrNoExchange if there is no unused exchange for a request.
+func (p *Player) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return 0, net.ErrClosed
+	}
+
+	var requests []Packet
+
+	p.written = append(p.written, b...)
+	requests, p.written = splitPackets(p.written)
+
+	for _, request := range requests {
+		if err := p.replay(request); err != nil {
+			return len(b), err
+		}
+	}
+
+	return len(b), nil
+}
+
+// replay queues recorded responses to request.
+func (p *Player) replay(request Packet) error {
+	for i, exchange := range p.exchanges {
+		if p.used[i] || exchange.Request.Type != request.Type || exchange.Request.Body != request.Body {
+			continue
+		}
+
+		p.used[i] = true
+
+		for _, response := range exchange.Responses {
+			if response.ID == exchange.Request.ID {
+				response.ID = request.ID
+			}
+
+			_, _ = rcon.NewPacket(response.Type, response.ID, response.Body).WriteTo(&p.pending)
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("rconrecord: %w: type %d, body %q", ErrNoExchange, request.Type, request.Body)
+}
+
+// Close closes the Player. Close is idempotent.
+func (p *Player) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.closed = true
+
+	return nil
+}
+
+// LocalAddr returns a placeholder address.
+func (p *Player) LocalAddr() net.Addr {
+	return playerAddr{}
+}
+
+// RemoteAddr returns a placeholder address.
+func (p *Player) RemoteAddr() net.Addr {
+	return playerAddr{}
+}
+
+// SetDeadline does nothing, because Player never blocks.
+func (p *Player) SetDeadline(time.Time) error {
+	return nil
+}
+
+// SetReadDeadline does nothing, because Player never blocks.
+func (p *Player) SetReadDeadline(time.Time) error {
+	return nil
+}
+
+// SetWriteDeadline does nothing, because Player never blocks.
+func (p *Player) SetWriteDeadline(time.Time) error {
+	return nil
+}
+
+// playerAddr is the network address of Player.
+type playerAddr struct{}
+
+// Network returns the address network name.
+func (playerAddr) Network() string {
+	return "rconrecord"
+}
+
+// String returns the address string.
+func (playerAddr) String() string {
+	return "player"
+}
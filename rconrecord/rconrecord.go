@@ -0,0 +1,115 @@
+// Package rconrecord records RCON sessions to cassettes and replays them
+// without the server, which allows to capture behavior of a real server once
+// and test against it offline.
+//
+// Recorder and Player are net.Conn transports which are passed to
+// rcon.NewConn. Recorder wraps a real connection and writes every request
+// packet with the response packets read after it to the cassette. Player
+// replays the recorded responses for matching requests.
+//
+// A cassette is a JSON Lines file. Each line is an exchange of one request
+// packet sent by the client and the response packets which the server sent
+// before the next request:
+//
+//	{"request":{"type":2,"id":1,"body":"status"},"responses":[{"type":0,"id":1,"body":"hostname: test"}]}
+//
+// Bodies are stored without the two terminating null bytes. Bodies must be
+// valid UTF-8, other bytes are replaced with U+FFFD.
+package rconrecord
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// packetHeaderSize is the size of packet size, id and type fields.
+const packetHeaderSize = 12
+
+// ErrNoExchange is returned by Player when there is no recorded exchange for
+// the request.
+var ErrNoExchange = errors.New("no recorded exchange for request")
+
+// Packet is a recorded RCON packet.
+type Packet struct {
+	Type int32  `json:"type"`
+	ID   int32  `json:"id"`
+	Body string `json:"body"`
+}
+
+// Exchange is a recorded request packet with its response packets.
+type Exchange struct {
+	Request   Packet   `json:"request"`
+	Responses []Packet `json:"responses"`
+}
+
+// ReadCassette reads exchanges from the cassette r.
+func ReadCassette(r io.Reader) ([]Exchange, error) {
+	var exchanges []Exchange
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var exchange Exchange
+		if err := json.Unmarshal(scanner.Bytes(), &exchange); err != nil {
+			return exchanges, fmt.Errorf("rconrecord: line %d: %w", line, err)
+		}
+
+		exchanges = append(exchanges, exchange)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return exchanges, fmt.Errorf("rconrecord: %w", err)
+	}
+
+	return exchanges, nil
+}
+
+// WriteExchange writes exchange to the cassette w as a single line.
+func WriteExchange(w io.Writer, exchange Exchange) error {
+	if exchange.Responses == nil {
+		exchange.Responses = []Packet{}
+	}
+
+	if err := json.NewEncoder(w).Encode(exchange); err != nil {
+		return fmt.Errorf("rconrecord: %w", err)
+	}
+
+	return nil
+}
+
+// splitPackets parses complete packets from the beginning of b and returns
+// them with the rest of b.
+func splitPackets(b []byte) ([]Packet, []byte) {
+	var packets []Packet
+
+	for len(b) >= 4 {
+		size := int(int32(binary.LittleEndian.Uint32(b)))
+		if size < packetHeaderSize-4 || len(b) < 4+size {
+			break
+		}
+
+		body := b[packetHeaderSize : 4+size]
+		if len(body) >= 2 && body[len(body)-2] == 0 && body[len(body)-1] == 0 {
+			body = body[:len(body)-2]
+		}
+
+		packets = append(packets, Packet{
+			ID:   int32(binary.LittleEndian.Uint32(b[4:])),
+			Type: int32(binary.LittleEndian.Uint32(b[8:])),
+			Body: string(body),
+		})
+
+		b = b[4+size:]
+	}
+
+	return packets, b
+}
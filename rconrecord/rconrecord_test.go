@@ -0,0 +1,138 @@
+package rconrecord_test
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon/rconrecord"
+	"github.com/gorcon/rcon/rcontest"
+)
+
+func commandHandler(c *rcontest.Context) {
+	switch c.Request().Body() {
+	case "status":
+		rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, "hostname: test").WriteTo(c.Conn())
+	default:
+		rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, "unknown command").WriteTo(c.Conn())
+	}
+}
+
+func record(t *testing.T, commands ...string) *bytes.Buffer {
+	t.Helper()
+
+	server := rcontest.NewServer(
+		rcontest.SetSettings(rcontest.Settings{Password: "password"}),
+		rcontest.SetCommandHandler(commandHandler),
+	)
+	defer server.Close()
+
+	netConn, err := net.Dial("tcp", server.Addr())
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	var cassette bytes.Buffer
+
+	conn, err := rcon.NewConn(rconrecord.NewRecorder(netConn, &cassette), "password")
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	for _, command := range commands {
+		if _, err := conn.Execute(command); err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+	}
+
+	if err := conn.Close(); err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	return &cassette
+}
+
+func TestRecorder(t *testing.T) {
+	cassette := record(t, "status")
+
+	exchanges, err := rconrecord.ReadCassette(cassette)
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	if len(exchanges) != 2 {
+		t.Fatalf("got exchanges len %d, want %d", len(exchanges), 2)
+	}
+
+	if exchanges[0].Request.Type != rcon.SERVERDATA_AUTH || len(exchanges[0].Responses) != 2 {
+		t.Errorf("got auth exchange %+v, want auth request with 2 responses", exchanges[0])
+	}
+
+	want := rconrecord.Exchange{
+		Request:   rconrecord.Packet{Type: rcon.SERVERDATA_EXECCOMMAND, ID: 1, Body: "status"},
+		Responses: []rconrecord.Packet{{Type: rcon.SERVERDATA_RESPONSE_VALUE, ID: 1, Body: "hostname: test"}},
+	}
+
+	got := exchanges[1]
+	if got.Request != want.Request || len(got.Responses) != 1 || got.Responses[0] != want.Responses[0] {
+		t.Errorf("got exchange %+v, want %+v", got, want)
+	}
+}
+
+func TestPlayer(t *testing.T) {
+	cassette := record(t, "status", "help")
+
+	t.Run("replay", func(t *testing.T) {
+		player, err := rconrecord.NewPlayer(bytes.NewReader(cassette.Bytes()))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		// Request ids differ from the recorded ones.
+		conn, err := rcon.NewConn(player, "password", rcon.SetRequestIDFunc(func() int32 { return 42 }))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		for command, resultWant := range map[string]string{"help": "unknown command", "status": "hostname: test"} {
+			result, err := conn.Execute(command)
+			if err != nil {
+				t.Fatalf("got err %q, want %v", err, nil)
+			}
+
+			if result != resultWant {
+				t.Errorf("got result %q, want %q", result, resultWant)
+			}
+		}
+	})
+
+	t.Run("no exchange", func(t *testing.T) {
+		player, err := rconrecord.NewPlayer(bytes.NewReader(cassette.Bytes()))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		conn, err := rcon.NewConn(player, "password")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		if _, err := conn.Execute("players"); !errors.Is(err, rconrecord.ErrNoExchange) {
+			t.Errorf("got err %q, want %q", err, rconrecord.ErrNoExchange)
+		}
+	})
+
+	t.Run("invalid cassette", func(t *testing.T) {
+		wantErrContains := "line 1"
+
+		_, err := rconrecord.NewPlayer(strings.NewReader("{"))
+		if err == nil || !strings.Contains(err.Error(), wantErrContains) {
+			t.Errorf("got err %q, want to contain %q", err, wantErrContains)
+		}
+	})
+}
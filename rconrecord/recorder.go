@@ -0,0 +1,98 @@
+package rconrecord
+
+import (
+	"io"
+	"net"
+	"sync"
+)
+
+// Recorder is a net.Conn which records packets passing through the wrapped
+// connection to a cassette. An exchange is written to the cassette when the
+// next request is sent or the Recorder is closed.
+type Recorder struct {
+	net.Conn
+	w        io.Writer
+	mu       sync.Mutex
+	current  *Exchange
+	written  []byte
+	read     []byte
+	writeErr error
+}
+
+// NewRecorder returns a Recorder which wraps conn and writes the cassette to w.
+func NewRecorder(conn net.Conn, w io.Writer) *Recorder {
+	return &Recorder{Conn: conn, w: w}
+}
+
+// Write writes b to the wrapped connection and records request packets.
+func (r *Recorder) Write(b []byte) (int, error) {
+	n, err := r.Conn.Write(b)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var packets []Packet
+
+	r.written = append(r.written, b[:n]...)
+	packets, r.written = splitPackets(r.written)
+
+	for _, packet := range packets {
+		r.flush()
+		r.current = &Exchange{Request: packet}
+	}
+
+	return n, err
+}
+
+// Read reads from the wrapped connection into b and records response packets.
+func (r *Recorder) Read(b []byte) (int, error) {
+	n, err := r.Conn.Read(b)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var packets []Packet
+
+	r.read = append(r.read, b[:n]...)
+	packets, r.read = splitPackets(r.read)
+
+	if len(packets) != 0 {
+		if r.current == nil {
+			// The server sent packets before any request.
+			r.current = &Exchange{}
+		}
+
+		r.current.Responses = append(r.current.Responses, packets...)
+	}
+
+	return n, err
+}
+
+// Close writes the last exchange to the cassette and closes the wrapped
+// connection. It returns the first error which occurred while writing the
+// cassette, if any.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.flush()
+
+	if err := r.Conn.Close(); err != nil {
+		return err
+	}
+
+	return r.writeErr
+}
+
+// flush writes the current exchange to the cassette.
+func (r *Recorder) flush() {
+	if r.current == nil {
+		return
+	}
+
+	if err := WriteExchange(r.w, *r.current); err != nil && r.writeErr == nil {
+		r.writeErr = err
+	}
+
+	r.current = nil
+}
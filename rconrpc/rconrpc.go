@@ -0,0 +1,141 @@
+// Package rconrpc provides Client which calls methods of a game API by name,
+// in the manner of net/rpc, on top of the string commands of RCON.
+//
+// Each method is registered with Method, which serializes args into the
+// command and parses the response into reply. Methods created by Command
+// follow the default contract: the command is the method command followed
+// by args separated by spaces, and the response is stored into reply as is.
+package rconrpc
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrUnknownMethod is returned by Call when the method is not registered.
+	ErrUnknownMethod = errors.New("unknown method")
+
+	// ErrUnsupportedArgs is returned when args can't be serialized into the
+	// command by the default contract.
+	ErrUnsupportedArgs = errors.New("unsupported args")
+
+	// ErrUnsupportedReply is returned when the response can't be stored into
+	// reply by the default contract.
+	ErrUnsupportedReply = errors.New("unsupported reply")
+)
+
+// Executor executes console commands on the server. It is implemented by
+// *rcon.Conn.
+type Executor interface {
+	Execute(command string) (string, error)
+}
+
+// Method serializes args of a call into the command and parses the response
+// into reply.
+type Method struct {
+	Encode func(args any) (string, error)
+	Decode func(response string, reply any) error
+}
+
+// Command returns Method of the default contract. Args are appended to
+// command separated by spaces and may be nil, a string, a []string or
+// a fmt.Stringer. Reply may be nil to discard the response, a *string or an
+// encoding.TextUnmarshaler.
+func Command(command string) Method {
+	return Method{
+		Encode: func(args any) (string, error) {
+			words, err := encodeArgs(args)
+			if err != nil {
+				return "", err
+			}
+
+			return strings.Join(append([]string{command}, words...), " "), nil
+		},
+		Decode: decodeReply,
+	}
+}
+
+// Client calls registered methods on the server. It is safe for concurrent
+// use if the executor is.
+type Client struct {
+	executor Executor
+	mu       sync.RWMutex
+	methods  map[string]Method
+}
+
+// NewClient creates Client which executes commands with executor.
+func NewClient(executor Executor) *Client {
+	return &Client{executor: executor, methods: make(map[string]Method)}
+}
+
+// Register adds method with name, replacing the one registered before.
+func (c *Client) Register(name string, method Method) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.methods[name] = method
+}
+
+// Call serializes args into the command of method, executes it and parses
+// the response into reply.
+func (c *Client) Call(method string, args any, reply any) error {
+	c.mu.RLock()
+	m, ok := c.methods[method]
+	c.mu.RUnlock()
+
+	if !ok {
+		return fmt.Errorf("rconrpc: %w: %s", ErrUnknownMethod, method)
+	}
+
+	command, err := m.Encode(args)
+	if err != nil {
+		return fmt.Errorf("rconrpc: %s: encode args: %w", method, err)
+	}
+
+	response, err := c.executor.Execute(command)
+	if err != nil {
+		return fmt.Errorf("rconrpc: %s: %w", method, err)
+	}
+
+	if err := m.Decode(response, reply); err != nil {
+		return fmt.Errorf("rconrpc: %s: decode reply: %w", method, err)
+	}
+
+	return nil
+}
+
+// encodeArgs returns words of args by the default contract.
+func encodeArgs(args any) ([]string, error) {
+	switch args := args.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{args}, nil
+	case []string:
+		return args, nil
+	case fmt.Stringer:
+		return []string{args.String()}, nil
+	default:
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedArgs, args)
+	}
+}
+
+// decodeReply stores response into reply by the default contract.
+func decodeReply(response string, reply any) error {
+	switch reply := reply.(type) {
+	case nil:
+		return nil
+	case *string:
+		*reply = response
+
+		return nil
+	case encoding.TextUnmarshaler:
+		return reply.UnmarshalText([]byte(response))
+	default:
+		return fmt.Errorf("%w: %T", ErrUnsupportedReply, reply)
+	}
+}
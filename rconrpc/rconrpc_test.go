@@ -0,0 +1,87 @@
+package rconrpc_test
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon/rconrpc"
+	"github.com/gorcon/rcon/rcontest"
+)
+
+func TestClient_Call(t *testing.T) {
+	server := rcontest.NewServer(rcontest.SetCommandHandler(func(c *rcontest.Context) {
+		var response string
+
+		switch command := c.Request().Body(); {
+		case command == "players":
+			response = "Players online: 3"
+		case strings.HasPrefix(command, "kick "):
+			response = "Kicked " + strings.TrimPrefix(command, "kick ")
+		default:
+			response = "Unknown command"
+		}
+
+		rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, response).WriteTo(c.Conn())
+	}))
+	defer server.Close()
+
+	conn, err := rcon.Dial(server.Addr(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	client := rconrpc.NewClient(conn)
+	client.Register("Kick", rconrpc.Command("kick"))
+	client.Register("Players", rconrpc.Method{
+		Encode: func(any) (string, error) { return "players", nil },
+		Decode: func(response string, reply any) error {
+			n, err := strconv.Atoi(strings.TrimPrefix(response, "Players online: "))
+			*reply.(*int) = n
+
+			return err
+		},
+	})
+
+	t.Run("default contract", func(t *testing.T) {
+		var reply string
+		if err := client.Call("Kick", []string{"Steve", "griefing"}, &reply); err != nil {
+			t.Fatal(err)
+		}
+
+		if reply != "Kicked Steve griefing" {
+			t.Errorf("got %q, want %q", reply, "Kicked Steve griefing")
+		}
+	})
+
+	t.Run("custom method", func(t *testing.T) {
+		var players int
+		if err := client.Call("Players", nil, &players); err != nil {
+			t.Fatal(err)
+		}
+
+		if players != 3 {
+			t.Errorf("got %d, want %d", players, 3)
+		}
+	})
+
+	t.Run("unknown method", func(t *testing.T) {
+		if err := client.Call("Ban", nil, nil); !errors.Is(err, rconrpc.ErrUnknownMethod) {
+			t.Errorf("got err %q, want %q", err, rconrpc.ErrUnknownMethod)
+		}
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		if err := client.Call("Kick", 42, nil); !errors.Is(err, rconrpc.ErrUnsupportedArgs) {
+			t.Errorf("got err %q, want %q", err, rconrpc.ErrUnsupportedArgs)
+		}
+
+		var reply int
+		if err := client.Call("Kick", "Steve", &reply); !errors.Is(err, rconrpc.ErrUnsupportedReply) {
+			t.Errorf("got err %q, want %q", err, rconrpc.ErrUnsupportedReply)
+		}
+	})
+}
@@ -0,0 +1,153 @@
+// Package rconrust contains helpers for Rust game servers, which wrap Execute
+// with parsing of the responses of Rust console commands.
+package rconrust
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorcon/rcon/rconparse"
+)
+
+// ErrInvalidStatus is returned when the response of the "status" command
+// cannot be parsed.
+var ErrInvalidStatus = errors.New("invalid status response")
+
+// Executor executes console commands on the server. It is implemented by
+// *rcon.Conn.
+type Executor interface {
+	Execute(command string) (string, error)
+}
+
+// Status is the parsed response of the "status" command. Fields contains all
+// "key : value" lines of the header, including ones which have their own field.
+type Status struct {
+	Hostname string
+	Version  string
+	Map      string
+	Players  []Player
+	Fields   map[string]string
+}
+
+// Player is a row of the players table of the "status" response. Columns which
+// the server doesn't report are left empty.
+type Player struct {
+	SteamID   string
+	Name      string
+	Ping      int
+	Connected time.Duration
+	Address   string
+}
+
+// GetStatus executes the "status" command and parses its response.
+func GetStatus(conn Executor) (Status, error) {
+	response, err := conn.Execute("status")
+	if err != nil {
+		return Status{}, err
+	}
+
+	return ParseStatus(response)
+}
+
+// ParseStatus parses the response of the "status" command. The header lines
+// are followed by the players table, for example:
+//
+//	hostname: My Server
+//	version : 2397 secure (secure mode enabled, connected to Steam3)
+//	map     : Procedural Map
+//	players : 1 (200 max) (0 queued) (0 joining)
+//
+//	id                name         ping connected addr           owner violation kicks
+//	76561198000000001 "Player One" 25   123.4s    10.0.0.1:28015       0.0       0
+//
+// Columns of the players table are found by the header line, so columns which
+// are added, removed or reordered by server updates are tolerated. For lines
+// which cannot be parsed it returns the rest of the status and an error
+// wrapping rconparse.ErrInvalidLine for each such line.
+func ParseStatus(response string) (Status, error) {
+	status := Status{Players: make([]Player, 0), Fields: make(map[string]string)}
+
+	var (
+		errs    []error
+		columns map[string]int
+	)
+
+	for _, line := range strings.Split(response, "\n") {
+		line = strings.TrimSpace(line)
+
+		switch {
+		case line == "":
+			continue
+		case columns == nil && strings.HasPrefix(line, "id "):
+			columns = make(map[string]int)
+
+			for i, name := range strings.Fields(line) {
+				columns[name] = i
+			}
+		case columns != nil:
+			player, err := parsePlayer(line, columns)
+			if err != nil {
+				errs = append(errs, err)
+
+				continue
+			}
+
+			status.Players = append(status.Players, player)
+		default:
+			key, value, ok := strings.Cut(line, ":")
+			if !ok {
+				errs = append(errs, fmt.Errorf("rconrust: %w: %q", rconparse.ErrInvalidLine, line))
+
+				continue
+			}
+
+			status.Fields[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+
+	if len(status.Fields) == 0 {
+		return status, fmt.Errorf("rconrust: %w: %q", ErrInvalidStatus, response)
+	}
+
+	status.Hostname = status.Fields["hostname"]
+	status.Version = status.Fields["version"]
+	status.Map = status.Fields["map"]
+
+	return status, errors.Join(errs...)
+}
+
+// parsePlayer parses a line of the players table. The name is quoted and may
+// contain spaces, other columns are separated by spaces.
+func parsePlayer(line string, columns map[string]int) (Player, error) {
+	start := strings.Index(line, "\"")
+	end := strings.LastIndex(line, "\"")
+
+	if start < 0 || end <= start {
+		return Player{}, fmt.Errorf("rconrust: %w: %q", rconparse.ErrInvalidLine, line)
+	}
+
+	values := strings.Fields(line[:start])
+	values = append(values, line[start+1:end])
+	values = append(values, strings.Fields(line[end+1:])...)
+
+	column := func(name string) string {
+		if i, ok := columns[name]; ok && i < len(values) {
+			return values[i]
+		}
+
+		return ""
+	}
+
+	if i, ok := columns["name"]; !ok || i != len(strings.Fields(line[:start])) {
+		return Player{}, fmt.Errorf("rconrust: %w: %q", rconparse.ErrInvalidLine, line)
+	}
+
+	player := Player{SteamID: column("id"), Name: column("name"), Address: column("addr")}
+	player.Ping, _ = strconv.Atoi(column("ping"))
+	player.Connected, _ = time.ParseDuration(column("connected"))
+
+	return player, nil
+}
@@ -0,0 +1,91 @@
+package rconrust_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon/rconparse"
+	"github.com/gorcon/rcon/rconrust"
+	"github.com/gorcon/rcon/rcontest"
+)
+
+const statusResponse = "hostname: My Rust Server\n" +
+	"version : 2397 secure (secure mode enabled, connected to Steam3)\n" +
+	"map     : Procedural Map\n" +
+	"players : 2 (200 max) (0 queued) (0 joining)\n\n" +
+	"id                name         ping connected addr                 owner violation kicks\n" +
+	"76561198000000001 \"Player One\" 25   123.4s    10.0.0.1:28015             0.0       0\n" +
+	"76561198000000002 \"Two\"        100  5.2s      10.0.0.2:28015             0.0       0\n"
+
+func TestGetStatus(t *testing.T) {
+	server := rcontest.NewServer(rcontest.SetCommandHandler(func(c *rcontest.Context) {
+		rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, statusResponse).WriteTo(c.Conn())
+	}))
+	defer server.Close()
+
+	conn, err := rcon.Dial(server.Addr(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	status, err := rconrust.GetStatus(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if status.Hostname != "My Rust Server" {
+		t.Errorf("got hostname %q, want %q", status.Hostname, "My Rust Server")
+	}
+
+	if status.Map != "Procedural Map" {
+		t.Errorf("got map %q, want %q", status.Map, "Procedural Map")
+	}
+
+	players := []rconrust.Player{
+		{SteamID: "76561198000000001", Name: "Player One", Ping: 25, Connected: 123400 * time.Millisecond, Address: "10.0.0.1:28015"},
+		{SteamID: "76561198000000002", Name: "Two", Ping: 100, Connected: 5200 * time.Millisecond, Address: "10.0.0.2:28015"},
+	}
+	if !reflect.DeepEqual(status.Players, players) {
+		t.Errorf("got players %+v, want %+v", status.Players, players)
+	}
+}
+
+func TestParseStatus(t *testing.T) {
+	t.Run("reordered columns", func(t *testing.T) {
+		status, err := rconrust.ParseStatus("hostname: Server\n" +
+			"id                name   addr           connected ping\n" +
+			"76561198000000001 \"One\" 10.0.0.1:28015 10s       30\n")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		players := []rconrust.Player{
+			{SteamID: "76561198000000001", Name: "One", Ping: 30, Connected: 10 * time.Second, Address: "10.0.0.1:28015"},
+		}
+		if !reflect.DeepEqual(status.Players, players) {
+			t.Errorf("got players %+v, want %+v", status.Players, players)
+		}
+	})
+
+	t.Run("partial", func(t *testing.T) {
+		status, err := rconrust.ParseStatus(statusResponse + "broken line\n")
+		if !errors.Is(err, rconparse.ErrInvalidLine) {
+			t.Errorf("got err %q, want %q", err, rconparse.ErrInvalidLine)
+		}
+
+		if status.Hostname != "My Rust Server" || len(status.Players) != 2 {
+			t.Errorf("got status %+v, want hostname and 2 players", status)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := rconrust.ParseStatus("Unknown command")
+		if !errors.Is(err, rconrust.ErrInvalidStatus) {
+			t.Errorf("got err %q, want %q", err, rconrust.ErrInvalidStatus)
+		}
+	})
+}
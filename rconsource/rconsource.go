@@ -0,0 +1,193 @@
+// Package rconsource contains helpers for Source engine game servers, such as
+// Counter-Strike and Team Fortress 2, which wrap Execute with parsing of
+// the responses of Source console commands.
+package rconsource
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gorcon/rcon/rconparse"
+)
+
+var (
+	// ErrUnknownCvar is returned when the server doesn't know the cvar.
+	ErrUnknownCvar = errors.New("unknown cvar")
+
+	// ErrInvalidCvarName is returned when the cvar name is empty or contains
+	// spaces, quotes or semicolons.
+	ErrInvalidCvarName = errors.New("invalid cvar name")
+
+	// ErrInvalidCvarValue is returned when the cvar value contains quotes or
+	// line breaks, which can't be escaped in Source console.
+	ErrInvalidCvarValue = errors.New("invalid cvar value")
+
+	// ErrInvalidStatus is returned when the response of the "status" command
+	// cannot be parsed.
+	ErrInvalidStatus = errors.New("invalid status response")
+)
+
+// Executor executes console commands on the server. It is implemented by
+// *rcon.Conn.
+type Executor interface {
+	Execute(command string) (string, error)
+}
+
+// ServerStatus is the parsed response of the "status" command. Fields
+// contains all "key : value" lines of the header, including ones which have
+// their own field.
+type ServerStatus struct {
+	Hostname string
+	Version  string
+	Address  string
+	Map      string
+	Players  []rconparse.Player
+	Fields   map[string]string
+}
+
+var cvarValue = regexp.MustCompile(`^"([^"]+)" = "([^"]*)"`)
+
+// GetCvar returns the value of the cvar name. The server responds to the cvar
+// name with a line like `"sv_cheats" = "0" ( def. "0" )`.
+func GetCvar(conn Executor, name string) (string, error) {
+	if err := checkCvarName(name); err != nil {
+		return "", err
+	}
+
+	response, err := conn.Execute(name)
+	if err != nil {
+		return "", err
+	}
+
+	for _, line := range strings.Split(response, "\n") {
+		match := cvarValue.FindStringSubmatch(strings.TrimSpace(line))
+		if match != nil && strings.EqualFold(match[1], name) {
+			return match[2], nil
+		}
+	}
+
+	return "", fmt.Errorf("rconsource: %w: %q", ErrUnknownCvar, name)
+}
+
+// SetCvar sets the cvar name to value.
+func SetCvar(conn Executor, name string, value string) error {
+	if err := checkCvarName(name); err != nil {
+		return err
+	}
+
+	if strings.ContainsAny(value, "\"\r\n") {
+		return fmt.Errorf("rconsource: %w: %q", ErrInvalidCvarValue, value)
+	}
+
+	response, err := conn.Execute(fmt.Sprintf("%s \"%s\"", name, value))
+	if err != nil {
+		return err
+	}
+
+	if strings.HasPrefix(strings.TrimSpace(response), "Unknown command") {
+		return fmt.Errorf("rconsource: %w: %q", ErrUnknownCvar, name)
+	}
+
+	return nil
+}
+
+// Status executes the "status" command and parses its response.
+func Status(conn Executor) (ServerStatus, error) {
+	response, err := conn.Execute("status")
+	if err != nil {
+		return ServerStatus{}, err
+	}
+
+	return ParseStatus(response)
+}
+
+// ParseStatus parses the response of the "status" command. The header lines
+// are followed by the players table, for example:
+//
+//	hostname: My Server
+//	map     : de_dust2
+//	# userid name uniqueid connected ping loss state rate adr
+//	#      2 1 "Player" STEAM_1:0:123 05:10 50 0 active 786432 10.0.0.1:27005
+//	#end
+func ParseStatus(response string) (ServerStatus, error) {
+	status := ServerStatus{Players: make([]rconparse.Player, 0), Fields: make(map[string]string)}
+
+	var errs []error
+
+	for _, line := range strings.Split(response, "\n") {
+		line = strings.TrimSpace(line)
+
+		switch {
+		case line == "" || line == "#end" || strings.HasPrefix(line, "# userid"):
+			continue
+		case strings.HasPrefix(line, "#"):
+			player, err := parsePlayer(line)
+			if err != nil {
+				errs = append(errs, err)
+
+				continue
+			}
+
+			status.Players = append(status.Players, player)
+		default:
+			key, value, ok := strings.Cut(line, ":")
+			if !ok {
+				continue
+			}
+
+			status.Fields[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+
+	if len(status.Fields) == 0 {
+		return status, fmt.Errorf("rconsource: %w: %q", ErrInvalidStatus, response)
+	}
+
+	status.Hostname = status.Fields["hostname"]
+	status.Version = status.Fields["version"]
+	status.Address = status.Fields["udp/ip"]
+	status.Map, _, _ = strings.Cut(status.Fields["map"], " ")
+
+	return status, errors.Join(errs...)
+}
+
+// parsePlayer parses a line of the players table. Columns after the quoted
+// name are "uniqueid connected ping loss state [rate] adr", bots only have
+// "uniqueid state".
+func parsePlayer(line string) (rconparse.Player, error) {
+	start := strings.Index(line, "\"")
+	end := strings.LastIndex(line, "\"")
+
+	if start < 0 || end <= start {
+		return rconparse.Player{}, fmt.Errorf("rconsource: %w: %q", rconparse.ErrInvalidLine, line)
+	}
+
+	player := rconparse.Player{Name: line[start+1 : end]}
+
+	columns := strings.Fields(line[end+1:])
+	if len(columns) > 0 {
+		player.ID = columns[0]
+	}
+
+	if len(columns) >= 5 {
+		player.Ping, _ = strconv.Atoi(columns[2])
+
+		if address := columns[len(columns)-1]; strings.Contains(address, ":") {
+			player.Address = address
+		}
+	}
+
+	return player, nil
+}
+
+// checkCvarName returns error if name cannot be sent as a cvar name.
+func checkCvarName(name string) error {
+	if name == "" || strings.ContainsAny(name, " \t\r\n\";") {
+		return fmt.Errorf("rconsource: %w: %q", ErrInvalidCvarName, name)
+	}
+
+	return nil
+}
@@ -0,0 +1,144 @@
+package rconsource_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon/rconparse"
+	"github.com/gorcon/rcon/rconsource"
+	"github.com/gorcon/rcon/rcontest"
+)
+
+const statusResponse = "hostname: My Server\n" +
+	"version : 1.38.2.2/13822 1401/8097 secure  [G:1:3635960]\n" +
+	"udp/ip  : 0.0.0.0:27015  (public ip: 203.0.113.1)\n" +
+	"os      :  Linux\n" +
+	"map     : de_dust2 at: 0 x, 0 y, 0 z\n" +
+	"players : 1 humans, 1 bots (16/0 max) (not hibernating)\n\n" +
+	"# userid name uniqueid connected ping loss state rate adr\n" +
+	"#  2 1 \"Player One\" STEAM_1:0:12345 05:10 50 0 active 786432 10.0.0.1:27005\n" +
+	"#  3 \"BOT\" BOT active 64\n" +
+	"#end\n"
+
+func handler(c *rcontest.Context) {
+	var response string
+
+	switch c.Request().Body() {
+	case "status":
+		response = statusResponse
+	case "sv_cheats":
+		response = "\"sv_cheats\" = \"0\" ( def. \"0\" )\n notify replicated\n - Allow cheats on server"
+	case "sv_cheats \"1\"":
+		response = ""
+	default:
+		response = "Unknown command \"" + c.Request().Body() + "\""
+	}
+
+	rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, response).WriteTo(c.Conn())
+}
+
+func dial(t *testing.T) *rcon.Conn {
+	t.Helper()
+
+	server := rcontest.NewServer(rcontest.SetSettings(rcontest.Settings{Password: "password"}), rcontest.SetCommandHandler(handler))
+	t.Cleanup(server.Close)
+
+	conn, err := rcon.Dial(server.Addr(), "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+func TestGetCvar(t *testing.T) {
+	conn := dial(t)
+
+	t.Run("known", func(t *testing.T) {
+		value, err := rconsource.GetCvar(conn, "sv_cheats")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if value != "0" {
+			t.Errorf("got value %q, want %q", value, "0")
+		}
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		_, err := rconsource.GetCvar(conn, "sv_unknown")
+		if !errors.Is(err, rconsource.ErrUnknownCvar) {
+			t.Errorf("got err %q, want %q", err, rconsource.ErrUnknownCvar)
+		}
+	})
+
+	t.Run("invalid name", func(t *testing.T) {
+		_, err := rconsource.GetCvar(conn, "sv_cheats; quit")
+		if !errors.Is(err, rconsource.ErrInvalidCvarName) {
+			t.Errorf("got err %q, want %q", err, rconsource.ErrInvalidCvarName)
+		}
+	})
+}
+
+func TestSetCvar(t *testing.T) {
+	conn := dial(t)
+
+	t.Run("known", func(t *testing.T) {
+		if err := rconsource.SetCvar(conn, "sv_cheats", "1"); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		err := rconsource.SetCvar(conn, "sv_unknown", "1")
+		if !errors.Is(err, rconsource.ErrUnknownCvar) {
+			t.Errorf("got err %q, want %q", err, rconsource.ErrUnknownCvar)
+		}
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		err := rconsource.SetCvar(conn, "hostname", "\"; quit")
+		if !errors.Is(err, rconsource.ErrInvalidCvarValue) {
+			t.Errorf("got err %q, want %q", err, rconsource.ErrInvalidCvarValue)
+		}
+	})
+}
+
+func TestStatus(t *testing.T) {
+	status, err := rconsource.Status(dial(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []rconparse.Player{
+		{Name: "Player One", ID: "STEAM_1:0:12345", Ping: 50, Address: "10.0.0.1:27005"},
+		{Name: "BOT", ID: "BOT"},
+	}
+
+	if status.Hostname != "My Server" {
+		t.Errorf("got hostname %q, want %q", status.Hostname, "My Server")
+	}
+
+	if status.Map != "de_dust2" {
+		t.Errorf("got map %q, want %q", status.Map, "de_dust2")
+	}
+
+	if status.Fields["os"] != "Linux" {
+		t.Errorf("got os %q, want %q", status.Fields["os"], "Linux")
+	}
+
+	if !reflect.DeepEqual(status.Players, want) {
+		t.Errorf("got players %+v, want %+v", status.Players, want)
+	}
+}
+
+func TestParseStatus(t *testing.T) {
+	_, err := rconsource.ParseStatus("Unknown command \"status\"")
+	if !errors.Is(err, rconsource.ErrInvalidStatus) {
+		t.Errorf("got err %q, want %q", err, rconsource.ErrInvalidStatus)
+	}
+}
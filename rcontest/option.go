@@ -1,5 +1,9 @@
 package rcontest
 
+import (
+	"crypto/tls"
+)
+
 // Option allows to inject Settings to Server.
 type Option func(s *Server)
 
@@ -23,3 +27,34 @@ func SetCommandHandler(handler HandlerFunc) Option {
 		s.SetCommandHandler(handler)
 	}
 }
+
+// SetMirrorHandler injects HandlerFunc with SERVERDATA_RESPONSE_VALUE requests
+// processing.
+func SetMirrorHandler(handler HandlerFunc) Option {
+	return func(s *Server) {
+		s.SetMirrorHandler(handler)
+	}
+}
+
+// SetExpectedCommands injects the sequence of commands the client must send.
+func SetExpectedCommands(commands []string) Option {
+	return func(s *Server) {
+		s.SetExpectedCommands(commands)
+	}
+}
+
+// SetTLSConfig injects the TLS config of the server, for example with
+// ClientAuth to require client certificates.
+func SetTLSConfig(config *tls.Config) Option {
+	return func(s *Server) {
+		s.TLS = config
+	}
+}
+
+// SetGameProfile injects the profile of the game which the server simulates,
+// for example RustProfile.
+func SetGameProfile(profile GameProfile) Option {
+	return func(s *Server) {
+		s.profile = &profile
+	}
+}
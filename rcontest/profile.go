@@ -0,0 +1,144 @@
+package rcontest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+
+	"github.com/gorcon/rcon"
+)
+
+// GameProfile describes quirks of a game server which Server applies to
+// packets written by handlers, so clients can be tested against a simulation
+// of the game.
+type GameProfile struct {
+	// Name is the game name.
+	Name string
+
+	// ResponseID is the fixed id of all response packets except failed auth.
+	// Zero keeps the ids written by handlers.
+	ResponseID int32
+
+	// Type4Preamble makes the server send an empty packet of type 4 before
+	// each packet of command responses.
+	Type4Preamble bool
+
+	// MaxBodySize is the maximum body size of SERVERDATA_RESPONSE_VALUE
+	// packets. Larger bodies are split into multiple packets. Zero disables
+	// splitting.
+	MaxBodySize int
+
+	// SentinelTrailer makes the server send the packet with 0x00 0x01 0x00 0x00
+	// body field after the mirrored empty SERVERDATA_RESPONSE_VALUE packet like
+	// SRCDSMirrorHandler.
+	SentinelTrailer bool
+
+	// Banner is put before the body of the first packet of each command
+	// response. Clients strip it with rcon.StripPrefix. Empty disables it.
+	Banner string
+}
+
+// Game profiles of known servers.
+var (
+	// SourceProfile is Source Dedicated Server, which sends the trailer packet
+	// after the mirrored sentinel of multi-packet responses.
+	SourceProfile = GameProfile{Name: "source", SentinelTrailer: true}
+
+	// MinecraftProfile is Minecraft server, which splits responses into
+	// packets of 4096 bytes bodies.
+	MinecraftProfile = GameProfile{Name: "minecraft", MaxBodySize: 4096}
+
+	// RustProfile is Rust server, which sends a packet of type 4 before
+	// command responses.
+	RustProfile = GameProfile{Name: "rust", Type4Preamble: true}
+
+	// ConanProfile is Conan Exiles server, which responds with id 42
+	// regardless of the request id.
+	ConanProfile = GameProfile{Name: "conanexiles", ResponseID: 42}
+
+	// ProjectZomboidProfile is Project Zomboid server, which puts a banner
+	// line before command responses.
+	ProjectZomboidProfile = GameProfile{Name: "projectzomboid", Banner: "Project Zomboid RCON\n"}
+)
+
+// profileConn is a connection which applies quirks of the game profile to
+// packets written in response to request.
+type profileConn struct {
+	net.Conn
+	profile *GameProfile
+	request *rcon.Packet
+	buffer  bytes.Buffer
+	banner  bool
+}
+
+// Write buffers b and writes complete packets with quirks of the profile.
+// Handlers may write packets in parts, so incomplete packets are kept until
+// the rest arrives.
+func (c *profileConn) Write(b []byte) (int, error) {
+	c.buffer.Write(b)
+
+	for c.buffer.Len() >= 4 {
+		size := int(int32(binary.LittleEndian.Uint32(c.buffer.Bytes())))
+		if size < 0 || c.buffer.Len() < 4+size {
+			break
+		}
+
+		var packet rcon.Packet
+		if _, err := packet.ReadFrom(bytes.NewReader(c.buffer.Next(4 + size))); err != nil {
+			return 0, err
+		}
+
+		if c.profile.Banner != "" && !c.banner && c.request.Type == rcon.SERVERDATA_EXECCOMMAND &&
+			packet.Type == rcon.SERVERDATA_RESPONSE_VALUE {
+			packet = *rcon.NewPacket(packet.Type, packet.ID, c.profile.Banner+packet.Body())
+			c.banner = true
+		}
+
+		for _, p := range c.profile.apply(&packet, c.request) {
+			if _, err := p.WriteTo(c.Conn); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	return len(b), nil
+}
+
+// apply returns packets which the game sends instead of packet in response
+// to request.
+func (p *GameProfile) apply(packet *rcon.Packet, request *rcon.Packet) []*rcon.Packet {
+	id := packet.ID
+	if p.ResponseID != 0 && id != -1 {
+		id = p.ResponseID
+	}
+
+	body := packet.Body()
+	bodies := []string{body}
+
+	if p.MaxBodySize > 0 && packet.Type == rcon.SERVERDATA_RESPONSE_VALUE {
+		bodies = bodies[:0]
+
+		for len(body) > p.MaxBodySize {
+			bodies = append(bodies, body[:p.MaxBodySize])
+			body = body[p.MaxBodySize:]
+		}
+
+		bodies = append(bodies, body)
+	}
+
+	var packets []*rcon.Packet
+
+	for _, fragment := range bodies {
+		if p.Type4Preamble && request.Type == rcon.SERVERDATA_EXECCOMMAND {
+			packets = append(packets, rcon.NewPacket(4, id, ""))
+		}
+
+		packets = append(packets, rcon.NewPacket(packet.Type, id, fragment))
+	}
+
+	if p.SentinelTrailer && request.Type == rcon.SERVERDATA_RESPONSE_VALUE && packet.Type == rcon.SERVERDATA_RESPONSE_VALUE && packet.Body() == "" {
+		packets = append(packets, rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, id, string([]byte{0x00, 0x01})))
+	}
+
+	return packets
+}
@@ -0,0 +1,157 @@
+package rcontest_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon/rcontest"
+)
+
+func TestSetGameProfile(t *testing.T) {
+	echoHandler := rcontest.SetCommandHandler(func(c *rcontest.Context) {
+		rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, c.Request().Body()).WriteTo(c.Conn())
+	})
+
+	t.Run("rust", func(t *testing.T) {
+		server := rcontest.NewServer(rcontest.SetGameProfile(rcontest.RustProfile), echoHandler)
+		defer server.Close()
+
+		client, err := rcon.Dial(server.Addr(), "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer client.Close()
+
+		for i := 0; i < 2; i++ {
+			response, err := client.Execute("status")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if response != "status" {
+				t.Errorf("got %q, want %q", response, "status")
+			}
+		}
+	})
+
+	t.Run("conan exiles", func(t *testing.T) {
+		server := rcontest.NewServer(rcontest.SetGameProfile(rcontest.ConanProfile), echoHandler)
+		defer server.Close()
+
+		if _, err := rcon.Dial(server.Addr(), ""); !errors.Is(err, rcon.ErrInvalidPacketID) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrInvalidPacketID)
+		}
+
+		client, err := rcon.Dial(server.Addr(), "", rcon.SetGameType(rcon.GameConanExiles))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer client.Close()
+
+		response, err := client.Execute("listplayers")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if response != "listplayers" {
+			t.Errorf("got %q, want %q", response, "listplayers")
+		}
+	})
+
+	t.Run("project zomboid", func(t *testing.T) {
+		server := rcontest.NewServer(rcontest.SetGameProfile(rcontest.ProjectZomboidProfile), echoHandler)
+		defer server.Close()
+
+		client, err := rcon.Dial(server.Addr(), "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer client.Close()
+
+		response, err := client.Execute("players")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if want := rcontest.ProjectZomboidProfile.Banner + "players"; response != want {
+			t.Errorf("got %q, want %q", response, want)
+		}
+
+		client, err = rcon.Dial(server.Addr(), "", rcon.SetResponsePipeline(rcon.StripPrefix(rcontest.ProjectZomboidProfile.Banner)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer client.Close()
+
+		if response, err = client.Execute("players"); err != nil {
+			t.Fatal(err)
+		}
+
+		if response != "players" {
+			t.Errorf("got %q, want %q", response, "players")
+		}
+	})
+
+	t.Run("minecraft", func(t *testing.T) {
+		long := strings.Repeat("a", 4096) + strings.Repeat("b", 100)
+
+		var packets int
+
+		server := rcontest.NewServer(
+			rcontest.SetGameProfile(rcontest.MinecraftProfile),
+			rcontest.SetCommandHandler(func(c *rcontest.Context) {
+				rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, long).WriteTo(c.Conn())
+			}),
+		)
+		defer server.Close()
+
+		client, err := rcon.Dial(server.Addr(), "", rcon.SetMultiPacket(true), rcon.SetReadInterceptor(func(packet rcon.Packet) (rcon.Packet, error) {
+			if packet.Type == rcon.SERVERDATA_RESPONSE_VALUE && packet.Body() != "" {
+				packets++
+			}
+
+			return packet, nil
+		}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer client.Close()
+
+		response, err := client.Execute("help")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if response != long {
+			t.Errorf("got response len %d, want %d", len(response), len(long))
+		}
+
+		if packets != 2 {
+			t.Errorf("got %d packets, want %d", packets, 2)
+		}
+	})
+
+	t.Run("source", func(t *testing.T) {
+		server := rcontest.NewServer(rcontest.SetGameProfile(rcontest.SourceProfile), echoHandler)
+		defer server.Close()
+
+		client, err := rcon.Dial(server.Addr(), "", rcon.SetMultiPacket(true))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer client.Close()
+
+		for _, command := range []string{"status", "users"} {
+			response, err := client.Execute(command)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if response != command {
+				t.Errorf("got %q, want %q", response, command)
+			}
+		}
+	})
+}
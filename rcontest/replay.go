@@ -0,0 +1,94 @@
+package rcontest
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon/rconrecord"
+)
+
+// replayer replays recorded responses to matching requests.
+type replayer struct {
+	mu        sync.Mutex
+	exchanges []rconrecord.Exchange
+	used      []bool
+}
+
+// NewReplayServer returns a running Server which replays the cassette at
+// cassettePath recorded by rconrecord.Recorder. The cassette is a JSON Lines
+// file described in rconrecord, so it may be edited by hand. Each request is
+// answered with the responses of the first unused exchange with the same
+// request type and body, the connection is closed if there is none. Commands
+// of the cassette are set as expected, so Err reports commands which the
+// client sent out of order or didn't send. NewReplayServer panics if the
+// cassette can't be read.
+func NewReplayServer(cassettePath string, options ...Option) *Server {
+	file, err := os.Open(cassettePath)
+	if err != nil {
+		panic(fmt.Sprintf("rcontest: failed to open cassette: %v", err))
+	}
+	defer file.Close()
+
+	exchanges, err := rconrecord.ReadCassette(file)
+	if err != nil {
+		panic(fmt.Sprintf("rcontest: failed to read cassette: %v", err))
+	}
+
+	r := &replayer{exchanges: exchanges, used: make([]bool, len(exchanges))}
+
+	var commands []string
+
+	for _, exchange := range exchanges {
+		if exchange.Request.Type == rcon.SERVERDATA_EXECCOMMAND {
+			commands = append(commands, exchange.Request.Body)
+		}
+	}
+
+	server := NewUnstartedServer(options...)
+	server.SetAuthHandler(r.handle)
+	server.SetCommandHandler(r.handle)
+	server.SetMirrorHandler(r.handle)
+	server.SetExpectedCommands(commands)
+	server.Start()
+
+	return server
+}
+
+// handle writes recorded responses to the request. Response ids equal to the
+// recorded request id are replaced with the id of the actual request.
+func (r *replayer) handle(c *Context) {
+	exchange, ok := r.next(c.Request())
+	if !ok {
+		_ = c.Conn().Close()
+
+		return
+	}
+
+	for _, response := range exchange.Responses {
+		if response.ID == exchange.Request.ID {
+			response.ID = c.Request().ID
+		}
+
+		_, _ = rcon.NewPacket(response.Type, response.ID, response.Body).WriteTo(c.Conn())
+	}
+}
+
+// next returns the first unused exchange matching request and marks it used.
+func (r *replayer) next(request *rcon.Packet) (rconrecord.Exchange, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, exchange := range r.exchanges {
+		if r.used[i] || exchange.Request.Type != request.Type || exchange.Request.Body != request.Body() {
+			continue
+		}
+
+		r.used[i] = true
+
+		return exchange, true
+	}
+
+	return rconrecord.Exchange{}, false
+}
@@ -0,0 +1,71 @@
+package rcontest_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon/rcontest"
+)
+
+const cassette = `{"request":{"type":3,"id":0,"body":"password"},"responses":[{"type":0,"id":0,"body":""},{"type":2,"id":0,"body":""}]}
+{"request":{"type":2,"id":1,"body":"status"},"responses":[{"type":0,"id":1,"body":"hostname: test"}]}
+{"request":{"type":2,"id":2,"body":"players"},"responses":[{"type":0,"id":2,"body":"Players connected (0):"}]}
+`
+
+func TestNewReplayServer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	if err := os.WriteFile(path, []byte(cassette), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("replayed", func(t *testing.T) {
+		server := rcontest.NewReplayServer(path)
+		defer server.Close()
+
+		client, err := rcon.Dial(server.Addr(), "password")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer client.Close()
+
+		for _, tt := range []struct{ command, want string }{
+			{command: "status", want: "hostname: test"},
+			{command: "players", want: "Players connected (0):"},
+		} {
+			response, err := client.Execute(tt.command)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if response != tt.want {
+				t.Errorf("got %q, want %q", response, tt.want)
+			}
+		}
+
+		if err := server.Err(); err != nil {
+			t.Errorf("got err %q, want %v", err, nil)
+		}
+	})
+
+	t.Run("unexpected command", func(t *testing.T) {
+		server := rcontest.NewReplayServer(path)
+		defer server.Close()
+
+		client, err := rcon.Dial(server.Addr(), "password")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer client.Close()
+
+		if _, err := client.Execute("kick Steve"); err == nil {
+			t.Error("got err nil, want error")
+		}
+
+		if err := server.Err(); !errors.Is(err, rcontest.ErrUnexpectedCommand) {
+			t.Errorf("got err %q, want %q", err, rcontest.ErrUnexpectedCommand)
+		}
+	})
+}
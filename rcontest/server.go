@@ -2,11 +2,13 @@
 package rcontest
 
 import (
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
 	"net"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/gorcon/rcon"
@@ -17,14 +19,20 @@ import (
 type Server struct {
 	Settings       Settings
 	Listener       net.Listener
+	TLS            *tls.Config
+	profile        *GameProfile
 	addr           string
 	authHandler    HandlerFunc
 	commandHandler HandlerFunc
+	mirrorHandler  HandlerFunc
 	connections    map[net.Conn]struct{}
 	quit           chan bool
 	wg             sync.WaitGroup
 	mu             sync.Mutex
 	closed         bool
+	expected       []string
+	received       int
+	err            error
 }
 
 // Settings contains configuration for RCON Server.
@@ -32,8 +40,22 @@ type Settings struct {
 	Password             string
 	AuthResponseDelay    time.Duration
 	CommandResponseDelay time.Duration
+
+	// AuthOrder is the order of packets which AuthHandler sends on
+	// successful authentication.
+	AuthOrder rcon.AuthOrder
 }
 
+var (
+	// ErrUnexpectedCommand is returned by Err when the client sent a command
+	// which does not match the sequence set by SetExpectedCommands.
+	ErrUnexpectedCommand = errors.New("unexpected command")
+
+	// ErrMissingCommands is returned by Err when the client sent fewer commands
+	// than were set by SetExpectedCommands.
+	ErrMissingCommands = errors.New("missing expected commands")
+)
+
 // HandlerFunc defines a function to serve RCON requests.
 type HandlerFunc func(c *Context)
 
@@ -41,11 +63,19 @@ type HandlerFunc func(c *Context)
 // SERVERDATA_AUTH_RESPONSE packet.
 func AuthHandler(c *Context) {
 	if c.Request().Body() == c.Server().Settings.Password {
-		// First write SERVERDATA_RESPONSE_VALUE packet with empty body.
-		_, _ = rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, "").WriteTo(c.Conn())
+		order := c.Server().Settings.AuthOrder
+
+		if order == rcon.EmptyThenAuth {
+			// First write SERVERDATA_RESPONSE_VALUE packet with empty body.
+			_, _ = rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, "").WriteTo(c.Conn())
+		}
 
 		// Than write SERVERDATA_AUTH_RESPONSE packet to allow authHandler success.
 		_, _ = rcon.NewPacket(rcon.SERVERDATA_AUTH_RESPONSE, rcon.SERVERDATA_AUTH_ID, "").WriteTo(c.Conn())
+
+		if order == rcon.AuthThenEmpty {
+			_, _ = rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, "").WriteTo(c.Conn())
+		}
 	} else {
 		// If authentication was failed, the ID must be assigned to -1.
 		_, _ = rcon.NewPacket(rcon.SERVERDATA_AUTH_RESPONSE, -1, string([]byte{0x00})).WriteTo(c.Conn())
@@ -58,6 +88,23 @@ func EmptyHandler(c *Context) {
 	_, _ = rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, "").WriteTo(c.Conn())
 }
 
+// MirrorHandler responses to SERVERDATA_RESPONSE_VALUE request with an empty
+// SERVERDATA_RESPONSE_VALUE packet with the same ID. Clients use it to detect
+// the end of multi-packet response.
+func MirrorHandler(c *Context) {
+	_, _ = rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, "").WriteTo(c.Conn())
+}
+
+// SRCDSMirrorHandler responses to SERVERDATA_RESPONSE_VALUE request like
+// MirrorHandler and then sends one more SERVERDATA_RESPONSE_VALUE packet with
+// the same ID and 0x00 0x01 0x00 0x00 in the body field like Source
+// Dedicated Server.
+func SRCDSMirrorHandler(c *Context) {
+	MirrorHandler(c)
+
+	_, _ = rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, string([]byte{0x00, 0x01})).WriteTo(c.Conn())
+}
+
 func newLocalListener() net.Listener {
 	l, err := net.Listen("tcp", "127.0.0.1:0")
 	if err != nil {
@@ -84,6 +131,7 @@ func NewUnstartedServer(options ...Option) *Server {
 		Listener:       newLocalListener(),
 		authHandler:    AuthHandler,
 		commandHandler: EmptyHandler,
+		mirrorHandler:  MirrorHandler,
 		connections:    make(map[net.Conn]struct{}),
 		quit:           make(chan bool),
 	}
@@ -105,12 +153,53 @@ func (s *Server) SetCommandHandler(handler HandlerFunc) {
 	s.commandHandler = handler
 }
 
-// Start starts a server from NewUnstartedServer.
+// SetExpectedCommands sets the sequence of commands the client must send.
+// Violations are recorded and can be retrieved with Err.
+func (s *Server) SetExpectedCommands(commands []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expected = commands
+	s.received = 0
+	s.err = nil
+}
+
+// Err returns the first violation of the sequence set by SetExpectedCommands
+// or ErrMissingCommands if the client has not sent all expected commands yet.
+// Err should be called after the client has finished sending commands.
+func (s *Server) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return s.err
+	}
+
+	if s.received < len(s.expected) {
+		return fmt.Errorf("rcontest: %w: got %d of %d, next %q",
+			ErrMissingCommands, s.received, len(s.expected), s.expected[s.received])
+	}
+
+	return nil
+}
+
+// SetMirrorHandler injects HandlerFunc with SERVERDATA_RESPONSE_VALUE requests
+// processing.
+func (s *Server) SetMirrorHandler(handler HandlerFunc) {
+	s.mirrorHandler = handler
+}
+
+// Start starts a server from NewUnstartedServer. If TLS is set, the server
+// accepts TLS connections only.
 func (s *Server) Start() {
 	if s.addr != "" {
 		panic("server already started")
 	}
 
+	if s.TLS != nil {
+		s.Listener = tls.NewListener(s.Listener, s.TLS)
+	}
+
 	s.addr = s.Listener.Addr().String()
 	s.goServe()
 }
@@ -192,16 +281,31 @@ func (s *Server) handle(conn net.Conn) {
 		s.wg.Done()
 	}()
 
+	// Clients which fail the handshake, for example without a required client
+	// certificate, are disconnected.
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		if err := tlsConn.Handshake(); err != nil {
+			return
+		}
+	}
+
 	for {
 		ctx, err := s.NewContext(conn)
 		if err != nil {
-			if !errors.Is(err, io.EOF) {
+			// Client may reset the connection if it closes it with unread
+			// response, for example after the command was canceled. Handler
+			// may close the connection to emulate a broken one.
+			if !errors.Is(err, io.EOF) && !errors.Is(err, syscall.ECONNRESET) && !errors.Is(err, net.ErrClosed) {
 				panic(fmt.Errorf("failed read request: %w", err))
 			}
 
 			return
 		}
 
+		if s.profile != nil {
+			ctx.conn = &profileConn{Conn: conn, profile: s.profile, request: ctx.Request()}
+		}
+
 		switch ctx.Request().Type {
 		case rcon.SERVERDATA_AUTH:
 			if s.Settings.AuthResponseDelay != 0 {
@@ -214,11 +318,34 @@ func (s *Server) handle(conn net.Conn) {
 				time.Sleep(s.Settings.CommandResponseDelay)
 			}
 
+			s.checkExpected(ctx.Request().Body())
 			s.commandHandler(ctx)
+		case rcon.SERVERDATA_RESPONSE_VALUE:
+			s.mirrorHandler(ctx)
 		}
 	}
 }
 
+// checkExpected compares received command with the expected sequence and
+// records the first violation.
+func (s *Server) checkExpected(command string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.expected == nil || s.err != nil {
+		return
+	}
+
+	switch {
+	case s.received >= len(s.expected):
+		s.err = fmt.Errorf("rcontest: %w: got %q, want no more commands", ErrUnexpectedCommand, command)
+	case s.expected[s.received] != command:
+		s.err = fmt.Errorf("rcontest: %w: got %q, want %q", ErrUnexpectedCommand, command, s.expected[s.received])
+	}
+
+	s.received++
+}
+
 // isRunning returns true if Server is running and false if is not.
 func (s *Server) isRunning() bool {
 	select {
@@ -234,7 +361,7 @@ func (s *Server) closeConn(conn net.Conn) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if err := conn.Close(); err != nil {
+	if err := conn.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
 		panic(fmt.Errorf("close conn error: %w", err))
 	}
 
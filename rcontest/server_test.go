@@ -101,4 +101,58 @@ func TestNewServer(t *testing.T) {
 			t.Errorf("got %q, want empty string", response)
 		}
 	})
+
+	t.Run("expected commands", func(t *testing.T) {
+		server := rcontest.NewServer(rcontest.SetExpectedCommands([]string{"setup", "query"}))
+		defer server.Close()
+
+		client, err := rcon.Dial(server.Addr(), "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer client.Close()
+
+		if _, err := client.Execute("setup"); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := server.Err(); !errors.Is(err, rcontest.ErrMissingCommands) {
+			t.Errorf("got err %q, want %q", err, rcontest.ErrMissingCommands)
+		}
+
+		if _, err := client.Execute("query"); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := server.Err(); err != nil {
+			t.Errorf("got err %q, want %v", err, nil)
+		}
+
+		if _, err := client.Execute("extra"); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := server.Err(); !errors.Is(err, rcontest.ErrUnexpectedCommand) {
+			t.Errorf("got err %q, want %q", err, rcontest.ErrUnexpectedCommand)
+		}
+	})
+
+	t.Run("unexpected command order", func(t *testing.T) {
+		server := rcontest.NewServer(rcontest.SetExpectedCommands([]string{"setup", "query"}))
+		defer server.Close()
+
+		client, err := rcon.Dial(server.Addr(), "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer client.Close()
+
+		if _, err := client.Execute("query"); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := server.Err(); !errors.Is(err, rcontest.ErrUnexpectedCommand) {
+			t.Errorf("got err %q, want %q", err, rcontest.ErrUnexpectedCommand)
+		}
+	})
 }
@@ -0,0 +1,35 @@
+package rcon
+
+import (
+	"fmt"
+	"log"
+	"runtime/debug"
+)
+
+// notify calls callback fn named name. If SetRecoverCallbacks is enabled,
+// a panic in fn is logged with the standard logger and the connection goes on.
+func (c *Conn) notify(name string, fn func()) {
+	if c.settings.recoverCallbacks {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("rcon: panic in %s: %v\n%s", name, r, debug.Stack())
+			}
+		}()
+	}
+
+	fn()
+}
+
+// callInterceptor passes packet to interceptor. If SetRecoverCallbacks is
+// enabled, a panic in the interceptor is returned as ErrCallbackPanic.
+func (c *Conn) callInterceptor(interceptor PacketInterceptor, packet Packet) (intercepted Packet, err error) {
+	if c.settings.recoverCallbacks {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("%w: %v", ErrCallbackPanic, r)
+			}
+		}()
+	}
+
+	return interceptor(packet)
+}
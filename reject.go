@@ -0,0 +1,46 @@
+package rcon
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultErrorPatterns contains prefixes of responses which servers commonly
+// send when they reject a command. Use it with SetErrorPatterns to add server
+// specific prefixes. Error prefixes end with a delimiter, so responses such as
+// "errors: 0" are not reported.
+var DefaultErrorPatterns = []string{
+	"error:",
+	"error ",
+	"unknown command",
+	"unknown or incomplete command",
+}
+
+// ExecuteChecked works like Execute and checks whether the server rejected
+// the command, which many servers report in the response body. If the
+// response starts with any of the error patterns, it returns the response and
+// an error wrapping ErrCommandRejected with the server message.
+func (c *Conn) ExecuteChecked(command string) (string, error) {
+	response, err := c.Execute(command)
+	if err != nil {
+		return response, err
+	}
+
+	return response, c.checkRejected(response)
+}
+
+// checkRejected returns ErrCommandRejected if response starts with any of the
+// error patterns. Patterns are matched case-insensitively ignoring leading
+// whitespace of the response.
+func (c *Conn) checkRejected(response string) error {
+	message := strings.TrimSpace(response)
+	lower := strings.ToLower(message)
+
+	for _, pattern := range c.settings.errorPatterns {
+		if pattern != "" && strings.HasPrefix(lower, strings.ToLower(pattern)) {
+			return fmt.Errorf("rcon: %w: %s", ErrCommandRejected, message)
+		}
+	}
+
+	return nil
+}
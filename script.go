@@ -0,0 +1,69 @@
+package rcon
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ScriptError is returned by RunScript when a command of the script failed.
+type ScriptError struct {
+	Line    int
+	Command string
+	Err     error
+}
+
+// Error returns the error message with the failed line number.
+func (e *ScriptError) Error() string {
+	return fmt.Sprintf("rcon: script line %d %q: %s", e.Line, e.Command, e.Err)
+}
+
+// Unwrap returns the error of the failed command.
+func (e *ScriptError) Unwrap() error {
+	return e.Err
+}
+
+// RunScript executes commands read from r one per line and returns their
+// responses. Lines are trimmed, empty lines and comments starting with # or
+// // are skipped. On the first failed command it returns the responses
+// gathered so far and ScriptError with the failed line. The script is
+// limited by SetBatchDeadline.
+func (c *Conn) RunScript(r io.Reader) ([]string, error) {
+	ctx, cancel := c.batchContext()
+	defer cancel()
+
+	var responses []string
+
+	scanner := bufio.NewScanner(r)
+
+	for line := 1; scanner.Scan(); line++ {
+		command := strings.TrimSpace(scanner.Text())
+		if command == "" || strings.HasPrefix(command, "#") || strings.HasPrefix(command, "//") {
+			continue
+		}
+
+		response, err := c.ExecuteContext(ctx, command)
+		if err != nil {
+			return responses, &ScriptError{Line: line, Command: command, Err: err}
+		}
+
+		responses = append(responses, response)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return responses, fmt.Errorf("rcon: %w", err)
+	}
+
+	return responses, nil
+}
+
+// batchContext returns context of a batch limited by SetBatchDeadline.
+func (c *Conn) batchContext() (context.Context, context.CancelFunc) {
+	if c.settings.batchDeadline > 0 {
+		return context.WithTimeout(context.Background(), c.settings.batchDeadline)
+	}
+
+	return context.WithCancel(context.Background())
+}
@@ -0,0 +1,79 @@
+package rcon_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon/rcontest"
+)
+
+func TestConn_RunScript(t *testing.T) {
+	server := rcontest.NewServer(rcontest.SetCommandHandler(commandHandler))
+	defer server.Close()
+
+	conn, err := rcon.Dial(server.Addr(), "")
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	t.Run("success", func(t *testing.T) {
+		script := "# greeting\n  help  \n\n// status\nstatus\n"
+
+		responses, err := conn.RunScript(strings.NewReader(script))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		responsesWant := []string{"lorem ipsum dolor sit amet", "unknown command"}
+		if fmt.Sprint(responses) != fmt.Sprint(responsesWant) {
+			t.Errorf("got responses %q, want %q", responses, responsesWant)
+		}
+	})
+
+	t.Run("failed line", func(t *testing.T) {
+		script := "help\n" + strings.Repeat("a", rcon.MaxCommandLen+1) + "\nhelp\n"
+
+		responses, err := conn.RunScript(strings.NewReader(script))
+		if !errors.Is(err, rcon.ErrCommandTooLong) {
+			t.Fatalf("got err %q, want %q", err, rcon.ErrCommandTooLong)
+		}
+
+		var scriptErr *rcon.ScriptError
+		if !errors.As(err, &scriptErr) || scriptErr.Line != 2 {
+			t.Errorf("got err %q, want script error on line %d", err, 2)
+		}
+
+		if len(responses) != 1 {
+			t.Errorf("got responses len %d, want %d", len(responses), 1)
+		}
+	})
+}
+
+func TestSetBatchDeadline(t *testing.T) {
+	server := rcontest.NewServer(
+		rcontest.SetSettings(rcontest.Settings{CommandResponseDelay: 40 * time.Millisecond}),
+		rcontest.SetCommandHandler(commandHandler),
+	)
+	defer server.Close()
+
+	conn, err := rcon.Dial(server.Addr(), "", rcon.SetBatchDeadline(100*time.Millisecond))
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	responses, err := conn.RunScript(strings.NewReader(strings.Repeat("help\n", 5)))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got err %q, want %q", err, context.DeadlineExceeded)
+	}
+
+	if len(responses) == 0 || len(responses) == 5 {
+		t.Errorf("got %d responses, want partial responses", len(responses))
+	}
+}
@@ -0,0 +1,30 @@
+package rcon
+
+import (
+	"fmt"
+	"net"
+)
+
+// setSocketBuffers sets sizes of the kernel receive and send buffers of conn.
+// Zero size keeps the system default. Connections other than *net.TCPConn are
+// left unchanged.
+func setSocketBuffers(conn net.Conn, readBuffer int, writeBuffer int) error {
+	tcpConn, ok := conn.(*net.TCPConn)
+	if !ok {
+		return nil
+	}
+
+	if readBuffer > 0 {
+		if err := tcpConn.SetReadBuffer(readBuffer); err != nil {
+			return fmt.Errorf("rcon: %w", err)
+		}
+	}
+
+	if writeBuffer > 0 {
+		if err := tcpConn.SetWriteBuffer(writeBuffer); err != nil {
+			return fmt.Errorf("rcon: %w", err)
+		}
+	}
+
+	return nil
+}
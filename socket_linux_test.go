@@ -0,0 +1,58 @@
+//go:build linux
+
+package rcon
+
+import (
+	"net"
+	"syscall"
+	"testing"
+)
+
+func TestSetSocketBuffers(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	const size = 64 * 1024
+
+	if err := setSocketBuffers(conn, size, size); err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	rawConn, err := conn.(*net.TCPConn).SyscallConn()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rawConn.Control(func(fd uintptr) {
+		for _, opt := range []int{syscall.SO_RCVBUF, syscall.SO_SNDBUF} {
+			got, err := syscall.GetsockoptInt(int(fd), syscall.SOL_SOCKET, opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Linux doubles the size for bookkeeping overhead and may clamp it.
+			if got < size {
+				t.Errorf("got option %d value %d, want at least %d", opt, got, size)
+			}
+		}
+	})
+
+	t.Run("non-TCP", func(t *testing.T) {
+		client, server := net.Pipe()
+		defer client.Close()
+		defer server.Close()
+
+		if err := setSocketBuffers(client, size, size); err != nil {
+			t.Errorf("got err %q, want %v", err, nil)
+		}
+	})
+}
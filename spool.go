@@ -0,0 +1,76 @@
+package rcon
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// spool is a buffer of the response body which moves to a temp file when its
+// size exceeds threshold. Zero threshold keeps the body in memory.
+type spool struct {
+	threshold int
+	buffer    bytes.Buffer
+	file      *os.File
+}
+
+// Write appends p to the memory buffer or to the temp file.
+func (s *spool) Write(p []byte) (int, error) {
+	if s.file == nil && s.threshold > 0 && s.buffer.Len()+len(p) > s.threshold {
+		file, err := os.CreateTemp("", "rcon-spool-*")
+		if err != nil {
+			return 0, fmt.Errorf("rcon: spool: %w", err)
+		}
+
+		s.file = file
+
+		if _, err := s.buffer.WriteTo(file); err != nil {
+			return 0, fmt.Errorf("rcon: spool: %w", err)
+		}
+	}
+
+	if s.file == nil {
+		return s.buffer.Write(p)
+	}
+
+	n, err := s.file.Write(p)
+	if err != nil {
+		return n, fmt.Errorf("rcon: spool: %w", err)
+	}
+
+	return n, nil
+}
+
+// reader returns the reader of the written body from the beginning.
+func (s *spool) reader() (io.ReadCloser, error) {
+	if s.file == nil {
+		return io.NopCloser(bytes.NewReader(s.buffer.Bytes())), nil
+	}
+
+	if _, err := s.file.Seek(0, io.SeekStart); err != nil {
+		s.discard()
+
+		return nil, fmt.Errorf("rcon: spool: %w", err)
+	}
+
+	return spoolFile{s.file}, nil
+}
+
+// discard removes the temp file if it is created.
+func (s *spool) discard() {
+	if s.file != nil {
+		_ = spoolFile{s.file}.Close()
+	}
+}
+
+// spoolFile is the temp file which is removed on Close.
+type spoolFile struct {
+	*os.File
+}
+
+// Close closes and removes the file.
+func (f spoolFile) Close() error {
+	return errors.Join(f.File.Close(), os.Remove(f.Name()))
+}
@@ -0,0 +1,71 @@
+package rcon_test
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon/rcontest"
+)
+
+func TestConn_ExecuteStream(t *testing.T) {
+	fragment := strings.Repeat("a", 1000)
+
+	server := rcontest.NewServer(rcontest.SetCommandHandler(func(c *rcontest.Context) {
+		for i := 0; i < 5; i++ {
+			rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, fragment).WriteTo(c.Conn())
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		threshold int
+		files     int
+	}{
+		{name: "memory", threshold: 0, files: 0},
+		{name: "below threshold", threshold: 10000, files: 0},
+		{name: "spooled", threshold: 2048, files: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			t.Setenv("TMPDIR", dir)
+
+			conn, err := rcon.Dial(server.Addr(), "", rcon.SetMultiPacket(true), rcon.SetSpoolThreshold(tt.threshold))
+			if err != nil {
+				t.Fatalf("got err %q, want %v", err, nil)
+			}
+			defer conn.Close()
+
+			reader, err := conn.ExecuteStream("log")
+			if err != nil {
+				t.Fatalf("got err %q, want %v", err, nil)
+			}
+
+			if files, _ := os.ReadDir(dir); len(files) != tt.files {
+				t.Errorf("got %d spool files, want %d", len(files), tt.files)
+			}
+
+			result, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatalf("got err %q, want %v", err, nil)
+			}
+
+			if string(result) != strings.Repeat(fragment, 5) {
+				t.Errorf("got result of %d bytes, want %d", len(result), 5*len(fragment))
+			}
+
+			if err := reader.Close(); err != nil {
+				t.Fatalf("got err %q, want %v", err, nil)
+			}
+
+			if files, _ := os.ReadDir(dir); len(files) != 0 {
+				t.Errorf("got %d spool files after close, want %d", len(files), 0)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package rcon
+
+// ConnState is a state of Conn lifecycle, which is passed to the function set
+// by SetConnStateFunc.
+type ConnState int32
+
+// Conn states.
+const (
+	// StateNew is the initial state before Dial starts connecting.
+	StateNew ConnState = iota
+
+	// StateConnecting is the state while Dial connects and authenticates.
+	StateConnecting
+
+	// StateAuthenticated is the state of idle authenticated connection.
+	StateAuthenticated
+
+	// StateExecuting is the state while a command is executed.
+	StateExecuting
+
+	// StateErrored is the state after connecting or a command failed.
+	// The next command moves the connection to StateExecuting again.
+	StateErrored
+
+	// StateClosed is the terminal state after Close.
+	StateClosed
+)
+
+// String returns the state name.
+func (s ConnState) String() string {
+	switch s {
+	case StateNew:
+		return "new"
+	case StateConnecting:
+		return "connecting"
+	case StateAuthenticated:
+		return "authenticated"
+	case StateExecuting:
+		return "executing"
+	case StateErrored:
+		return "errored"
+	case StateClosed:
+		return "closed"
+	default:
+		return "unknown"
+	}
+}
+
+// State returns the current state of the connection.
+func (c *Conn) State() ConnState {
+	return ConnState(c.state.Load())
+}
+
+// setState moves the connection to state and calls the state function if the
+// state is changed. StateClosed is never left.
+func (c *Conn) setState(state ConnState) {
+	for {
+		old := c.State()
+		if old == state || old == StateClosed {
+			return
+		}
+
+		if c.state.CompareAndSwap(int32(old), int32(state)) {
+			if c.settings.connStateFunc != nil {
+				c.notify("conn state func", func() { c.settings.connStateFunc(old, state) })
+			}
+
+			return
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package rcon
+
+import (
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// ConnStats is a snapshot of Conn counters.
+type ConnStats struct {
+	Commands   int64
+	Errors     int64
+	BytesIn    int64
+	BytesOut   int64
+	AvgLatency time.Duration
+	MaxLatency time.Duration
+	Uptime     time.Duration
+}
+
+// connStats contains Conn counters which are safe for concurrent use.
+type connStats struct {
+	commands     atomic.Int64
+	errors       atomic.Int64
+	bytesIn      atomic.Int64
+	bytesOut     atomic.Int64
+	totalLatency atomic.Int64
+	maxLatency   atomic.Int64
+	lastUsed     atomic.Int64
+	connected    time.Time
+}
+
+// Stats returns counters of executed commands and transferred bytes since the
+// connection was established. It is safe to call concurrently with Execute.
+func (c *Conn) Stats() ConnStats {
+	stats := ConnStats{
+		Commands:   c.stats.commands.Load(),
+		Errors:     c.stats.errors.Load(),
+		BytesIn:    c.stats.bytesIn.Load(),
+		BytesOut:   c.stats.bytesOut.Load(),
+		MaxLatency: time.Duration(c.stats.maxLatency.Load()),
+		Uptime:     time.Since(c.stats.connected),
+	}
+
+	if stats.Commands != 0 {
+		stats.AvgLatency = time.Duration(c.stats.totalLatency.Load() / stats.Commands)
+	}
+
+	return stats
+}
+
+// recordCommand updates counters with the command result.
+func (s *connStats) recordCommand(latency time.Duration, err error) {
+	s.commands.Add(1)
+	s.totalLatency.Add(int64(latency))
+
+	if err != nil {
+		s.errors.Add(1)
+	}
+
+	for {
+		maxLatency := s.maxLatency.Load()
+		if int64(latency) <= maxLatency || s.maxLatency.CompareAndSwap(maxLatency, int64(latency)) {
+			return
+		}
+	}
+}
+
+// countingReader counts bytes read from r.
+type countingReader struct {
+	r io.Reader
+	n *atomic.Int64
+}
+
+// Read reads from r and adds the number of read bytes to the counter.
+func (cr countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n.Add(int64(n))
+
+	return n, err
+}
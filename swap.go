@@ -0,0 +1,74 @@
+package rcon
+
+import (
+	"errors"
+)
+
+// ErrSwapSelf is returned by Swap when the connection is swapped with itself.
+var ErrSwapSelf = errors.New("connection swapped with itself")
+
+// Swap replaces the transport of the connection with the one of newConn, for
+// example to move to a migrated server behind the same *Conn handle. newConn
+// must be authenticated, it is consumed by Swap and behaves as closed after
+// it. Swap waits until in-flight commands of both connections have finished,
+// commands called during the swap wait for it and run on the new transport.
+// The old transport is closed. Settings of the connection are kept, the
+// address and password of newConn are used for reconnects. The locks of both
+// connections are taken in a fixed order, so when two connections are swapped
+// into each other concurrently, one Swap succeeds and the other returns
+// ErrConnClosed.
+func (c *Conn) Swap(newConn *Conn) error {
+	if newConn == c {
+		return ErrSwapSelf
+	}
+
+	// Locks are ordered by id to not deadlock with newConn.Swap(c).
+	first, second := c, newConn
+	if first.id > second.id {
+		first, second = second, first
+	}
+
+	first.mu.Lock()
+	defer first.mu.Unlock()
+
+	second.mu.Lock()
+	defer second.mu.Unlock()
+
+	if c.IsClosed() || newConn.IsClosed() {
+		return ErrConnClosed
+	}
+
+	if !newConn.authenticated.Load() {
+		return ErrNotAuthenticated
+	}
+
+	// newConn is closed without closing its transport, which now belongs to c.
+	newConn.closed.Store(true)
+
+	if timer := newConn.idleTimer.Load(); timer != nil {
+		timer.Stop()
+	}
+
+	newConn.audit.close()
+	newConn.setState(StateClosed)
+
+	c.deadlineMu.Lock()
+	old := c.conn
+	c.conn = newConn.conn
+	c.deadlineMu.Unlock()
+
+	c.network = newConn.network
+	c.address = newConn.address
+	c.password = newConn.password
+	c.dialed = newConn.dialed
+	c.truncated = false
+	c.authenticated.Store(true)
+	c.setState(StateAuthenticated)
+	c.markUsed()
+
+	if old != nil {
+		_ = old.Close()
+	}
+
+	return nil
+}
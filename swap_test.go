@@ -0,0 +1,118 @@
+package rcon_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon/rcontest"
+)
+
+func TestConn_Swap(t *testing.T) {
+	newServer := func(response string) *rcontest.Server {
+		return rcontest.NewServer(rcontest.SetCommandHandler(func(c *rcontest.Context) {
+			rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, response).WriteTo(c.Conn())
+		}))
+	}
+
+	oldServer := newServer("old")
+	defer oldServer.Close()
+
+	replacement := newServer("new")
+	defer replacement.Close()
+
+	conn, err := rcon.Dial(oldServer.Addr(), "")
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	newConn, err := rcon.Dial(replacement.Addr(), "")
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer newConn.Close()
+
+	if err := conn.Swap(conn); !errors.Is(err, rcon.ErrSwapSelf) {
+		t.Errorf("got err %q, want %q", err, rcon.ErrSwapSelf)
+	}
+
+	if err := conn.Swap(newConn); err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	result, err := conn.Execute("status")
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	if result != "new" {
+		t.Errorf("got result %q, want %q", result, "new")
+	}
+
+	if _, err := newConn.Execute("status"); !errors.Is(err, rcon.ErrConnClosed) {
+		t.Errorf("got err %q, want %q", err, rcon.ErrConnClosed)
+	}
+
+	if err := conn.Swap(newConn); !errors.Is(err, rcon.ErrConnClosed) {
+		t.Errorf("got err %q, want %q", err, rcon.ErrConnClosed)
+	}
+}
+
+func TestConn_Swap_concurrent(t *testing.T) {
+	server := rcontest.NewServer(rcontest.SetCommandHandler(commandHandler))
+	defer server.Close()
+
+	for i := 0; i < 20; i++ {
+		first, err := rcon.Dial(server.Addr(), "")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		second, err := rcon.Dial(server.Addr(), "")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		errs := make([]error, 2)
+
+		var wg sync.WaitGroup
+
+		wg.Add(3)
+
+		go func() {
+			defer wg.Done()
+
+			errs[0] = first.Swap(second)
+		}()
+
+		go func() {
+			defer wg.Done()
+
+			errs[1] = second.Swap(first)
+		}()
+
+		go func() {
+			defer wg.Done()
+
+			// Addresses are read while the transport is swapped.
+			_, _ = first.LocalAddr(), second.RemoteAddr()
+		}()
+
+		wg.Wait()
+
+		if (errs[0] == nil) == (errs[1] == nil) {
+			t.Fatalf("got errs %q, want one nil and one %q", errs, rcon.ErrConnClosed)
+		}
+
+		for _, err := range errs {
+			if err != nil && !errors.Is(err, rcon.ErrConnClosed) {
+				t.Errorf("got err %q, want %q", err, rcon.ErrConnClosed)
+			}
+		}
+
+		first.Close()
+		second.Close()
+	}
+}
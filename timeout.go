@@ -0,0 +1,44 @@
+package rcon
+
+import (
+	"context"
+	"time"
+)
+
+// commandTimeoutKey is the context key of the timeout of ExecuteTimeout.
+type commandTimeoutKey struct{}
+
+// ExecuteTimeout works like Execute but timeout replaces the deadline of
+// SetDeadline for this command, so it may be longer for commands which are
+// known to be slow and shorter for fast ones. Like the connection deadline it
+// limits each network operation of the command. Zero timeout means no limit.
+// The timeout of SetAdaptiveTimeout is not used for this command. The most
+// specific limit wins: timeout takes precedence over the deadline set by
+// Conn.SetDeadline, Conn.SetReadDeadline or Conn.SetWriteDeadline, which takes
+// precedence over the SetDeadline option. Any of them is cut short by an
+// earlier context deadline.
+func (c *Conn) ExecuteTimeout(command string, timeout time.Duration) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ctx := context.WithValue(context.Background(), commandTimeoutKey{}, timeout)
+
+	return c.execute(ctx, command, "")
+}
+
+// commandTimeout returns the timeout of ExecuteTimeout from ctx or timeout if
+// ctx has none.
+func commandTimeout(ctx context.Context, timeout time.Duration) time.Duration {
+	if override, ok := ctx.Value(commandTimeoutKey{}).(time.Duration); ok {
+		return override
+	}
+
+	return timeout
+}
+
+// hasCommandTimeout reports whether ctx carries the timeout of ExecuteTimeout.
+func hasCommandTimeout(ctx context.Context) bool {
+	_, ok := ctx.Value(commandTimeoutKey{}).(time.Duration)
+
+	return ok
+}
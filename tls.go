@@ -0,0 +1,70 @@
+package rcon
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+)
+
+// DialTLS works like Dial but connects over TLS with config, for example to
+// a secured RCON gateway. See SetTLSConfig.
+func DialTLS(address string, password string, config *tls.Config, options ...Option) (*Conn, error) {
+	options = append(options[:len(options):len(options)], SetTLSConfig(config))
+
+	return Dial(address, password, options...)
+}
+
+// tlsEnabled reports whether the connection uses TLS.
+func (c *Conn) tlsEnabled() bool {
+	return c.settings.tlsConfig != nil || c.settings.clientCertFile != ""
+}
+
+// tlsClient performs TLS handshake on conn. The dial timeout limits the
+// handshake too.
+func (c *Conn) tlsClient(ctx context.Context, conn net.Conn) (net.Conn, error) {
+	config, err := c.tlsClientConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if c.settings.dialTimeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, c.settings.dialTimeout)
+		defer cancel()
+	}
+
+	tlsConn := tls.Client(conn, config)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		return nil, fmt.Errorf("rcon: tls handshake: %w", err)
+	}
+
+	return tlsConn, nil
+}
+
+// tlsClientConfig returns a copy of the TLS config with the client certificate
+// of SetClientCert and the server name from the address if they are set.
+func (c *Conn) tlsClientConfig() (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if c.settings.tlsConfig != nil {
+		config = c.settings.tlsConfig.Clone()
+	}
+
+	if c.settings.clientCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.settings.clientCertFile, c.settings.clientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("rcon: load client certificate: %w", err)
+		}
+
+		config.Certificates = append(config.Certificates, cert)
+	}
+
+	if config.ServerName == "" {
+		if host, _, err := net.SplitHostPort(c.address); err == nil {
+			config.ServerName = host
+		}
+	}
+
+	return config, nil
+}
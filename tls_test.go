@@ -0,0 +1,155 @@
+package rcon_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io/fs"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon/rcontest"
+)
+
+// testCert is a certificate signed by the test CA with its PEM encoding.
+type testCert struct {
+	cert    tls.Certificate
+	certPEM []byte
+	keyPEM  []byte
+}
+
+// newTestCert creates a certificate for 127.0.0.1 signed by parent or
+// a self-signed CA certificate if parent is nil.
+func newTestCert(t *testing.T, parent *testCert, usage x509.ExtKeyUsage) *testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "rcon test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+
+	signer, signerKey := template, any(key)
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+	} else {
+		signer, signerKey = parent.cert.Leaf, parent.cert.PrivateKey
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cert.Leaf, err = x509.ParseCertificate(der); err != nil {
+		t.Fatal(err)
+	}
+
+	return &testCert{cert: cert, certPEM: certPEM, keyPEM: keyPEM}
+}
+
+func TestSetClientCert(t *testing.T) {
+	ca := newTestCert(t, nil, x509.ExtKeyUsageAny)
+	serverCert := newTestCert(t, ca, x509.ExtKeyUsageServerAuth)
+	clientCert := newTestCert(t, ca, x509.ExtKeyUsageClientAuth)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert.Leaf)
+
+	server := rcontest.NewServer(
+		rcontest.SetTLSConfig(&tls.Config{
+			Certificates: []tls.Certificate{serverCert.cert},
+			ClientAuth:   tls.RequireAndVerifyClientCert,
+			ClientCAs:    pool,
+			MinVersion:   tls.VersionTLS12,
+		}),
+		rcontest.SetCommandHandler(commandHandler),
+	)
+	defer server.Close()
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "client.crt")
+	keyFile := filepath.Join(dir, "client.key")
+
+	if err := os.WriteFile(certFile, clientCert.certPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(keyFile, clientCert.keyPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	config := &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+
+	t.Run("client cert", func(t *testing.T) {
+		conn, err := rcon.DialTLS(server.Addr(), "", config, rcon.SetClientCert(certFile, keyFile))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		result, err := conn.Execute("help")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if result != "lorem ipsum dolor sit amet" {
+			t.Errorf("got result %q, want %q", result, "lorem ipsum dolor sit amet")
+		}
+	})
+
+	t.Run("no client cert", func(t *testing.T) {
+		conn, err := rcon.DialTLS(server.Addr(), "", config)
+		if err == nil {
+			conn.Close()
+			t.Fatalf("got err %v, want error", err)
+		}
+	})
+
+	t.Run("missing cert file", func(t *testing.T) {
+		_, err := rcon.DialTLS(server.Addr(), "", config, rcon.SetClientCert(filepath.Join(dir, "missing.crt"), keyFile))
+		if !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("got err %q, want %q", err, fs.ErrNotExist)
+		}
+	})
+
+	t.Run("invalid cert file", func(t *testing.T) {
+		_, err := rcon.DialTLS(server.Addr(), "", config, rcon.SetClientCert(keyFile, keyFile))
+		if err == nil {
+			t.Errorf("got err %v, want error", err)
+		}
+	})
+}
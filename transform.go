@@ -0,0 +1,41 @@
+package rcon
+
+import (
+	"strings"
+)
+
+// PrefixCommand returns a command pipeline function which adds prefix to
+// commands which don't start with it, for example "/" for Minecraft plugins.
+func PrefixCommand(prefix string) func(command string) (string, error) {
+	return func(command string) (string, error) {
+		if strings.HasPrefix(command, prefix) {
+			return command, nil
+		}
+
+		return prefix + command, nil
+	}
+}
+
+// AppendNewline returns a command pipeline function which terminates commands
+// with "\n" like SetAppendNewline.
+func AppendNewline() func(command string) (string, error) {
+	return func(command string) (string, error) {
+		return command + "\n", nil
+	}
+}
+
+// TrimResponse returns a response pipeline function which removes trailing
+// whitespace and nulls like SetTrimResponse.
+func TrimResponse() func(response string) (string, error) {
+	return func(response string) (string, error) {
+		return strings.TrimRight(response, trimCutset), nil
+	}
+}
+
+// StripPrefix returns a response pipeline function which removes prefix from
+// responses, for example a banner which the server puts before every response.
+func StripPrefix(prefix string) func(response string) (string, error) {
+	return func(response string) (string, error) {
+		return strings.TrimPrefix(response, prefix), nil
+	}
+}
@@ -0,0 +1,53 @@
+package rcon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrResponseCorrupt is returned when the response is rejected by the
+// validator set by SetResponseValidator.
+var ErrResponseCorrupt = errors.New("response corrupt")
+
+// validateResponse checks body of command with the response validator. While
+// the response is corrupt and attempts of SetResponseValidatorRetries are left,
+// packetBody of command is sent again on the same connection.
+func (c *Conn) validateResponse(ctx context.Context, command string, packetBody string, body []byte) ([]byte, error) {
+	if c.settings.responseValidator == nil {
+		return body, nil
+	}
+
+	err := c.checkResponse(command, body)
+
+	for attempt := 0; err != nil && attempt < c.settings.validatorRetries; attempt++ {
+		if err = sleepContext(ctx, c.settings.validatorBackoff); err != nil {
+			return body, err
+		}
+
+		response, sendErr := c.roundTrip(ctx, packetBody)
+		if sendErr != nil {
+			return response.body, contextErr(ctx, sendErr)
+		}
+
+		body = response.body
+
+		if err = c.checkBanned(body); err != nil {
+			return body, err
+		}
+
+		err = c.checkResponse(command, body)
+	}
+
+	return body, err
+}
+
+// checkResponse returns ErrResponseCorrupt if the response validator rejects
+// body of command.
+func (c *Conn) checkResponse(command string, body []byte) error {
+	if err := c.settings.responseValidator(command, string(body)); err != nil {
+		return fmt.Errorf("rcon: %w: %w", ErrResponseCorrupt, err)
+	}
+
+	return nil
+}
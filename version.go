@@ -0,0 +1,89 @@
+package rcon
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// versionCommands contains commands which print the server version of known
+// game types.
+var versionCommands = map[GameType]string{
+	GameSource:    "version",
+	GameMinecraft: "version",
+}
+
+// Version is a semantic version parsed by SemVer.
+type Version struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+var semVerPattern = regexp.MustCompile(`(\d+)\.(\d+)(?:\.(\d+))?`)
+
+// ServerVersion executes the version command of the game set by SetGameType
+// and returns the trimmed response, for example to gate version specific
+// features with SemVer. For game types without a version command it returns
+// an error wrapping errors.ErrUnsupported. Supported game types are
+// GameSource and GameMinecraft servers with Bukkit compatible plugins.
+func (c *Conn) ServerVersion() (string, error) {
+	command, ok := versionCommands[c.settings.gameType]
+	if !ok {
+		return "", fmt.Errorf("rcon: server version of game %s: %w", c.settings.gameType, errors.ErrUnsupported)
+	}
+
+	response, err := c.Execute(command)
+	if err != nil {
+		return "", err
+	}
+
+	version := strings.TrimSpace(response)
+	if version == "" {
+		return "", fmt.Errorf("rcon: %w: %q", ErrUnexpectedResponse, response)
+	}
+
+	return version, nil
+}
+
+// SemVer parses the first "major.minor" or "major.minor.patch" version in
+// version, for example the response of ServerVersion. It reports false if
+// version contains none.
+func SemVer(version string) (Version, bool) {
+	match := semVerPattern.FindStringSubmatch(version)
+	if match == nil {
+		return Version{}, false
+	}
+
+	var v Version
+
+	v.Major, _ = strconv.Atoi(match[1])
+	v.Minor, _ = strconv.Atoi(match[2])
+
+	if match[3] != "" {
+		v.Patch, _ = strconv.Atoi(match[3])
+	}
+
+	return v, true
+}
+
+// Compare returns -1, 0 or +1 if v is less than, equal to or greater than w.
+func (v Version) Compare(w Version) int {
+	for _, d := range [3]int{v.Major - w.Major, v.Minor - w.Minor, v.Patch - w.Patch} {
+		switch {
+		case d < 0:
+			return -1
+		case d > 0:
+			return 1
+		}
+	}
+
+	return 0
+}
+
+// String returns the version in "major.minor.patch" format.
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
@@ -0,0 +1,90 @@
+package rcon_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon/rcontest"
+)
+
+func TestConn_ServerVersion(t *testing.T) {
+	const sourceVersion = "Protocol version 24\nExe version 1.38.7.9 (csgo)\nExe build: 19:36:35 Jun 17 2023 (8459) (730)\n"
+
+	server := rcontest.NewServer(rcontest.SetCommandHandler(func(c *rcontest.Context) {
+		response := "Unknown command"
+		if c.Request().Body() == "version" {
+			response = sourceVersion
+		}
+
+		rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, response).WriteTo(c.Conn())
+	}))
+	defer server.Close()
+
+	t.Run("source", func(t *testing.T) {
+		conn, err := rcon.Dial(server.Addr(), "", rcon.SetGameType(rcon.GameSource))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		version, err := conn.ServerVersion()
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if want := "Protocol version 24\nExe version 1.38.7.9 (csgo)\nExe build: 19:36:35 Jun 17 2023 (8459) (730)"; version != want {
+			t.Errorf("got version %q, want %q", version, want)
+		}
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		conn, err := rcon.Dial(server.Addr(), "", rcon.SetGameType(rcon.GameRust))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		if _, err := conn.ServerVersion(); !errors.Is(err, errors.ErrUnsupported) {
+			t.Errorf("got err %q, want %q", err, errors.ErrUnsupported)
+		}
+	})
+}
+
+func TestSemVer(t *testing.T) {
+	tests := []struct {
+		version string
+		want    rcon.Version
+		ok      bool
+	}{
+		{version: "This server is running Paper version 1.20.4-496 (MC: 1.20.4)", want: rcon.Version{Major: 1, Minor: 20, Patch: 4}, ok: true},
+		{version: "Exe version 1.38.7.9 (csgo)", want: rcon.Version{Major: 1, Minor: 38, Patch: 7}, ok: true},
+		{version: "v2.1", want: rcon.Version{Major: 2, Minor: 1}, ok: true},
+		{version: "Unknown command", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			got, ok := rcon.SemVer(tt.version)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("got %v %t, want %v %t", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+
+	t.Run("compare", func(t *testing.T) {
+		v := rcon.Version{Major: 1, Minor: 20, Patch: 4}
+
+		if got := v.Compare(rcon.Version{Major: 1, Minor: 19, Patch: 9}); got != 1 {
+			t.Errorf("got %d, want %d", got, 1)
+		}
+
+		if got := v.Compare(rcon.Version{Major: 1, Minor: 20, Patch: 4}); got != 0 {
+			t.Errorf("got %d, want %d", got, 0)
+		}
+
+		if got := v.Compare(rcon.Version{Major: 2}); got != -1 {
+			t.Errorf("got %d, want %d", got, -1)
+		}
+	})
+}
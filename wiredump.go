@@ -0,0 +1,54 @@
+package rcon
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+)
+
+// writePacket writes packet to c.conn and to the wire dump if it is set.
+func (c *Conn) writePacket(packet *Packet) error {
+	if c.settings.wireDump == nil {
+		n, err := packet.writeTo(c.conn, c.settings.byteOrder)
+		c.stats.bytesOut.Add(n)
+
+		return err
+	}
+
+	var raw bytes.Buffer
+
+	_, _ = packet.writeTo(&raw, c.settings.byteOrder)
+	c.dump("write", raw.Bytes())
+
+	n, err := raw.WriteTo(c.conn)
+	c.stats.bytesOut.Add(n)
+
+	return err
+}
+
+// dumpReader returns reader of c.conn which counts read bytes and records them
+// if the wire dump is set. The returned function writes recorded bytes to the
+// wire dump.
+func (c *Conn) dumpReader() (io.Reader, func()) {
+	r := countingReader{r: c.conn, n: &c.stats.bytesIn}
+
+	if c.settings.wireDump == nil {
+		return r, func() {}
+	}
+
+	var raw bytes.Buffer
+
+	return io.TeeReader(r, &raw), func() {
+		c.dump("read", raw.Bytes())
+	}
+}
+
+// dump writes b to the wire dump in hex and ASCII. Write errors are ignored.
+func (c *Conn) dump(direction string, b []byte) {
+	if len(b) == 0 {
+		return
+	}
+
+	_, _ = fmt.Fprintf(c.settings.wireDump, "%s %d bytes\n%s", direction, len(b), hex.Dump(b))
+}